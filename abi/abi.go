@@ -1,12 +1,21 @@
 package abi
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
 	"fmt"
+	"math"
 	"math/big"
 	"reflect"
+	"sort"
+	"unicode/utf8"
 
+	"gx/ipfs/QmR8BauakNcBa3RbE4nbQu76PDiJgoQgz8AJdhJuiU4TAw/go-cid"
 	cbor "gx/ipfs/QmRoARq3nkUb13HSKZGepCZSWe5GrVPwx7xURJGZ7KWv9V/go-ipld-cbor"
 	"gx/ipfs/QmSKyB5faguXT4NqbrXpnRXqaVj5DhSm7x9BtzFydBY1UK/go-leb128"
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
 	"gx/ipfs/QmY5Grm8pJdiSSVsYxx4uNRgweY72EmYwuSDbRnbFok3iY/go-libp2p-peer"
 
 	"github.com/filecoin-project/go-filecoin/address"
@@ -16,6 +25,15 @@ import (
 // ErrInvalidType is returned when processing a zero valued 'Type' (aka Invalid)
 var ErrInvalidType = fmt.Errorf("invalid type")
 
+// ErrNilValue is returned when processing a nil *Value
+var ErrNilValue = fmt.Errorf("nil value")
+
+// MaxIntegerBits is the largest bit length of an Integer accepted by
+// Deserialize, which bounds the cost of math on decoded values. DeserializeSafe
+// also applies it to the LEB128 encoded AttoFIL, BytesAmount and ChannelID
+// types. Zero disables the limit.
+var MaxIntegerBits = 2048
+
 // Type represents a type that can be passed through the filecoin ABI
 type Type uint64
 
@@ -24,28 +42,72 @@ const (
 	Invalid = Type(iota)
 	// Address is a address.Address
 	Address
-	// AttoFIL is a *types.AttoFIL
+	// AttoFIL is a *types.AttoFIL, the type used for token amounts. Negative
+	// amounts cannot be serialized.
 	AttoFIL
 	// BytesAmount is a *types.BytesAmount
 	BytesAmount
 	// ChannelID is a *types.ChannelID
 	ChannelID
-	// BlockHeight is a *types.BlockHeight
+	// BlockHeight is a *types.BlockHeight, encoded as a fixed width 8 byte
+	// big-endian unsigned number. Nil and heights that don't fit into a uint64
+	// can't be serialized.
 	BlockHeight
-	// Integer is a *big.Int
+	// Integer is a *big.Int. It is encoded as a sign byte (0x00 for
+	// non-negative, 0x01 for negative values) followed by the big-endian bytes
+	// of its absolute value. Zero is encoded as the lone sign byte 0x00, never
+	// as an empty slice, so that it can't be confused with a missing value.
+	// Deserialize still decodes an empty slice to zero and accepts leading zero
+	// bytes and a negative zero, so one number has several encodings it
+	// accepts. Consensus code must decode Integers with DeserializeCanonical,
+	// which only accepts the encoding Serialize produces.
+	//
+	// Integers used to be encoded as their unsigned big-endian bytes, without a
+	// sign byte. Data written in that format can't be decoded any more: the old
+	// encoding of 1 ([0x01]) now decodes to 0, 261 ([0x01 0x05]) decodes to -5
+	// and 5 ([0x05]) is rejected.
 	Integer
-	// Bytes is a []byte
+	// Bytes is a []byte. Nil and empty slices are not distinguished: both are
+	// serialized to an empty non-nil slice, and Deserialize always returns a
+	// non-nil slice.
 	Bytes
 	// String is a string
 	String
 	// UintArray is an array of uint64
 	UintArray
-	// PeerID is a libp2p peer ID
+	// PeerID is a libp2p peer ID, encoded as its binary multihash.
 	PeerID
 	// SectorID is a uint64
 	SectorID
 	// CommitmentsMap is a map of stringified sector id (uint64) to commitments
 	CommitmentsMap
+	// Boolean is a bool
+	Boolean
+	// Uint64 is a uint64 encoded as 8 big-endian bytes. Since SectorID shares
+	// the same go type, ToValues maps uint64 to SectorID; Uint64 values have to
+	// be constructed explicitly.
+	Uint64
+	// Cid is a cid.Cid
+	Cid
+	// Int256 is a non-negative *big.Int below 2^256, encoded as 32 big-endian
+	// bytes. Since Integer shares the same go type, ToValues maps *big.Int to
+	// Integer; Int256 values have to be constructed explicitly.
+	Int256
+	// Rational is a *big.Rat, encoded as two length prefixed segments holding
+	// the numerator (in the Integer encoding) and the big-endian denominator.
+	Rational
+	// Float64 is a float64 encoded as the 8 big-endian bytes of its IEEE 754
+	// representation. Floating point arithmetic is not consensus safe, so it is
+	// meant for off-chain tooling only and must not be used in actor methods.
+	// ToValues doesn't map float64, values have to be constructed explicitly.
+	Float64
+	// Int64 is an int64 encoded as 8 big-endian bytes in two's complement.
+	Int64
+	// AddressSet is a []address.Address holding a set of addresses, encoded
+	// as the concatenated addresses in ascending byte order with duplicates
+	// removed. Deserialize rejects duplicates and unsorted input and returns
+	// the addresses in sorted order.
+	AddressSet
 )
 
 func (t Type) String() string {
@@ -76,50 +138,467 @@ func (t Type) String() string {
 		return "uint64"
 	case CommitmentsMap:
 		return "map[string]Commitments"
+	case Boolean:
+		return "bool"
+	case Uint64:
+		return "uint64be"
+	case Cid:
+		return "cid.Cid"
+	case Int256:
+		return "int256"
+	case Rational:
+		return "*big.Rat"
+	case Float64:
+		return "float64"
+	case Int64:
+		return "int64"
+	case AddressSet:
+		return "[]address.Address"
 	default:
+		if name, ok := declaredName(t); ok {
+			return name
+		}
 		return "<unknown type>"
 	}
 }
 
+// IsFixedWidth returns the length of the serialized values of the type, if it
+// is the same for all values. Variable length and unknown types return false.
+func (t Type) IsFixedWidth() (int, bool) {
+	switch t {
+	case Address:
+		return address.Length, true
+	case Boolean:
+		return 1, true
+	case BlockHeight, Uint64, Int64, Float64:
+		return 8, true
+	case Int256:
+		return 32, true
+	default:
+		return 0, false
+	}
+}
+
+// TypeFromString parses the representation returned by Type.String back into
+// a Type.
+func TypeFromString(s string) (Type, error) {
+	if s == Invalid.String() {
+		return Invalid, ErrInvalidType
+	}
+
+	for t := range typeTable {
+		if t.String() == s {
+			return t, nil
+		}
+	}
+
+	registryLk.RLock()
+	defer registryLk.RUnlock()
+	for t, name := range declared {
+		if name == s {
+			return t, nil
+		}
+	}
+	return Invalid, fmt.Errorf("unknown type: %q", s)
+}
+
+// SupportedTypes returns all built-in types and all types registered with
+// RegisterType, ordered by their numeric value. Invalid is not included.
+func SupportedTypes() []Type {
+	out := make([]Type, 0, len(typeTable))
+	for t := range typeTable {
+		out = append(out, t)
+	}
+
+	registryLk.RLock()
+	for t := range registry {
+		out = append(out, t)
+	}
+	registryLk.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
 // Value pairs a go value with its ABI type
 type Value struct {
 	Type Type
 	Val  interface{}
 }
 
+// String renders the type name together with a human readable form of the
+// value, e.g. `[]byte(beef)` or `string("foo")`. It never panics, so it is safe
+// to use on values that failed validation.
 func (av *Value) String() string {
+	if av == nil {
+		return "<nil>"
+	}
+	if _, ok := typeTable[av.Type]; !ok {
+		if av.Type == Invalid {
+			return "<invalid>"
+		}
+		if _, ok := declaredName(av.Type); ok {
+			return fmt.Sprintf("%s(%v)", av.Type, av.Val)
+		}
+		return "<unknown type>"
+	}
+
 	switch av.Type {
-	case Invalid:
-		return "<invalid>"
 	case Address:
-		return av.Val.(address.Address).String()
-	case AttoFIL:
-		return av.Val.(*types.AttoFIL).String()
-	case BytesAmount:
-		return av.Val.(*types.BytesAmount).String()
-	case ChannelID:
-		return av.Val.(*types.ChannelID).String()
-	case BlockHeight:
-		return av.Val.(*types.BlockHeight).String()
-	case Integer:
-		return av.Val.(*big.Int).String()
+		return fmt.Sprintf("%s(%s)", av.Type, av.Val)
 	case Bytes:
-		return string(av.Val.([]byte))
+		return fmt.Sprintf("%s(%x)", av.Type, av.Val)
 	case String:
-		return av.Val.(string)
-	case UintArray:
-		return fmt.Sprint(av.Val.([]uint64))
+		return fmt.Sprintf("%s(%q)", av.Type, av.Val)
 	case PeerID:
-		return av.Val.(peer.ID).String()
+		if id, ok := av.Val.(peer.ID); ok {
+			return fmt.Sprintf("%s(%s)", av.Type, peer.IDB58Encode(id))
+		}
+		return fmt.Sprintf("%s(%v)", av.Type, av.Val)
+	default:
+		return fmt.Sprintf("%s(%v)", av.Type, av.Val)
+	}
+}
+
+// Redacted works like String, but renders Bytes values, which may hold keys
+// or signatures, as their length and a hash prefix instead of their content.
+func (av *Value) Redacted() string {
+	if av == nil || av.Type != Bytes {
+		return av.String()
+	}
+
+	b, ok := av.Val.([]byte)
+	if !ok {
+		return av.String()
+	}
+
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf("%s(len=%d, sha256=%x...)", av.Type, len(b), sum[:8])
+}
+
+// Equals reports whether both values have the same type and hold equal
+// values. Numeric values are compared by value, so differently constructed
+// *big.Ints holding the same number are equal. Floats are compared by their
+// bits instead, so that equal values have equal encodings: 0 and -0 differ
+// and a NaN only equals the identical NaN.
+func (av *Value) Equals(other *Value) bool {
+	if av == nil || other == nil {
+		return av == other
+	}
+	if av.Type != other.Type {
+		return false
+	}
+	if a, ok := av.Val.(float64); ok {
+		b, ok := other.Val.(float64)
+		return ok && math.Float64bits(a) == math.Float64bits(b)
+	}
+	if av.Type == Invalid || reflect.DeepEqual(av.Val, other.Val) {
+		return true
+	}
+
+	switch a := av.Val.(type) {
+	case *big.Int:
+		b, ok := other.Val.(*big.Int)
+		return ok && a != nil && b != nil && a.Cmp(b) == 0
+	case *big.Rat:
+		b, ok := other.Val.(*big.Rat)
+		return ok && a != nil && b != nil && a.Cmp(b) == 0
+	case []byte:
+		b, ok := other.Val.([]byte)
+		return ok && bytes.Equal(a, b)
+	case *types.AttoFIL:
+		b, ok := other.Val.(*types.AttoFIL)
+		return ok && a != nil && b != nil && a.Equal(b)
+	case *types.BytesAmount:
+		b, ok := other.Val.(*types.BytesAmount)
+		return ok && a != nil && b != nil && a.Equal(b)
+	case *types.ChannelID:
+		b, ok := other.Val.(*types.ChannelID)
+		return ok && a != nil && b != nil && a.Equal(b)
+	case *types.BlockHeight:
+		b, ok := other.Val.(*types.BlockHeight)
+		return ok && a != nil && b != nil && a.Equal(b)
+	case []address.Address:
+		// sets are equal if they have the same members, like their encodings
+		b, ok := other.Val.([]address.Address)
+		if !ok {
+			return false
+		}
+		as, bs := normalizeAddressSet(a), normalizeAddressSet(b)
+		if len(as) != len(bs) {
+			return false
+		}
+		for i := range as {
+			if as[i] != bs[i] {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// ConstantTimeEquals works like Equals, but compares Bytes values in constant
+// time. Use it for secrets like signatures or MACs. Only the contents are
+// protected, the lengths of the values still leak.
+func (av *Value) ConstantTimeEquals(other *Value) bool {
+	if av == nil || other == nil || av.Type != Bytes || other.Type != Bytes {
+		return av.Equals(other)
+	}
+
+	a, ok := av.Val.([]byte)
+	if !ok {
+		return av.Equals(other)
+	}
+	b, ok := other.Val.([]byte)
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// IsZero reports whether the value holds the zero value of its type, e.g. an
+// empty byte slice, an empty string or a zero *big.Int. Invalid and nil values
+// are considered zero.
+func (av *Value) IsZero() bool {
+	if av == nil || av.Type == Invalid || av.Val == nil {
+		return true
+	}
+
+	switch v := av.Val.(type) {
+	case address.Address:
+		return v.Empty()
+	case *big.Int:
+		return v == nil || v.Sign() == 0
+	case *big.Rat:
+		return v == nil || v.Sign() == 0
+	case []byte:
+		return len(v) == 0
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case uint64:
+		return v == 0
+	case float64:
+		return v == 0
+	case int64:
+		return v == 0
+	case []uint64:
+		return len(v) == 0
+	case []address.Address:
+		return len(v) == 0
+	case peer.ID:
+		return v == ""
+	case map[string]types.Commitments:
+		return len(v) == 0
+	case cid.Cid:
+		return !v.Defined()
+	case *types.AttoFIL:
+		return v == nil || v.IsZero()
+	case *types.BytesAmount:
+		return v == nil || v.IsZero()
+	case *types.ChannelID:
+		return v == nil || v.Equal(types.NewChannelID(0))
+	case *types.BlockHeight:
+		return v == nil || v.Equal(types.NewBlockHeight(0))
+	default:
+		return false
+	}
+}
+
+// Clone returns a deep copy of the value that doesn't share any mutable state
+// with the original.
+func (av *Value) Clone() *Value {
+	if av == nil {
+		return nil
+	}
+	if av.Type == Invalid {
+		return &Value{}
+	}
+
+	val := av.Val
+	switch v := av.Val.(type) {
+	case *big.Int:
+		if v != nil {
+			val = new(big.Int).Set(v)
+		}
+	case *big.Rat:
+		if v != nil {
+			val = new(big.Rat).Set(v)
+		}
+	case []byte:
+		if v != nil {
+			val = append([]byte{}, v...)
+		}
+	case []uint64:
+		if v != nil {
+			val = append([]uint64{}, v...)
+		}
+	case []address.Address:
+		if v != nil {
+			val = append([]address.Address{}, v...)
+		}
+	case *types.AttoFIL:
+		if v != nil {
+			val = types.NewAttoFILFromBytes(v.Bytes())
+		}
+	case *types.BytesAmount:
+		if v != nil {
+			val = types.NewBytesAmountFromBytes(v.Bytes())
+		}
+	case *types.ChannelID:
+		if v != nil {
+			val = types.NewChannelIDFromBytes(v.Bytes())
+		}
+	case *types.BlockHeight:
+		if v != nil {
+			val = types.NewBlockHeightFromBytes(v.Bytes())
+		}
+	case map[string]types.Commitments:
+		if v != nil {
+			m := make(map[string]types.Commitments, len(v))
+			for k, c := range v {
+				m[k] = c
+			}
+			val = m
+		}
+	}
+
+	return &Value{Type: av.Type, Val: val}
+}
+
+// SerializedSize returns the length of the encoding Serialize would produce
+// for the value. The common types are measured without serializing them.
+func (av *Value) SerializedSize() (int, error) {
+	if av == nil {
+		return 0, ErrNilValue
+	}
+
+	switch av.Type {
+	case Address:
+		if _, ok := av.Val.(address.Address); !ok {
+			return 0, &typeError{address.Address{}, av.Val}
+		}
+		return address.Length, nil
+	case Integer:
+		intgr, ok := av.Val.(*big.Int)
+		if !ok {
+			return 0, &typeError{&big.Int{}, av.Val}
+		}
+		return 1 + (intgr.BitLen()+7)/8, nil
+	case Bytes:
+		b, ok := av.Val.([]byte)
+		if !ok {
+			return 0, &typeError{[]byte{}, av.Val}
+		}
+		return len(b), nil
+	case String:
+		s, ok := av.Val.(string)
+		if !ok {
+			return 0, &typeError{"", av.Val}
+		}
+		return len(s), nil
 	case SectorID:
-		return fmt.Sprint(av.Val.(uint64))
-	case CommitmentsMap:
-		return fmt.Sprint(av.Val.(map[string]types.Commitments))
+		n, ok := av.Val.(uint64)
+		if !ok {
+			return 0, &typeError{uint64(0), av.Val}
+		}
+
+		// leb128 stores 7 bits per byte and needs at least one byte
+		size := 1
+		for n >= 0x80 {
+			n >>= 7
+			size++
+		}
+		return size, nil
+	case Boolean:
+		if _, ok := av.Val.(bool); !ok {
+			return 0, &typeError{false, av.Val}
+		}
+		return 1, nil
+	case Uint64:
+		if _, ok := av.Val.(uint64); !ok {
+			return 0, &typeError{uint64(0), av.Val}
+		}
+		return 8, nil
+	case Int64:
+		if _, ok := av.Val.(int64); !ok {
+			return 0, &typeError{int64(0), av.Val}
+		}
+		return 8, nil
+	case Float64:
+		if _, ok := av.Val.(float64); !ok {
+			return 0, &typeError{float64(0), av.Val}
+		}
+		return 8, nil
 	default:
-		return "<unknown type>"
+		data, err := av.Serialize()
+		if err != nil {
+			return 0, err
+		}
+		return len(data), nil
 	}
 }
 
+// maxValueLength bounds the length of Bytes and String values accepted by
+// Validate.
+const maxValueLength = DefaultMaxFrameSize
+
+// Validate checks the invariants of the value: its type is known, Val holds
+// the go type expected for it, pointers are not nil, numbers are in the range
+// of their type and Bytes and String values are of sane length. Values of
+// custom types are checked by encoding them with their codec. It returns the
+// first violation found.
+func (av *Value) Validate() error {
+	if av == nil {
+		return ErrNilValue
+	}
+	if av.Type == Invalid {
+		return ErrInvalidType
+	}
+
+	rt, ok := typeTable[av.Type]
+	if !ok {
+		codec, err := customCodec(av.Type)
+		if err != nil {
+			return err
+		}
+		_, err = codec.Encode(av.Val)
+		return err
+	}
+	if reflect.TypeOf(av.Val) != rt {
+		return &typeError{reflect.Zero(rt).Interface(), av.Val}
+	}
+
+	if rt.Kind() == reflect.Ptr && reflect.ValueOf(av.Val).IsNil() {
+		return fmt.Errorf("nil %s value", av.Type)
+	}
+
+	switch v := av.Val.(type) {
+	case *big.Int:
+		if av.Type == Int256 {
+			return checkInt256(v)
+		}
+	case *types.AttoFIL:
+		if v.IsNegative() {
+			return fmt.Errorf("token amount cannot be negative: %s", v)
+		}
+	case []byte:
+		if len(v) > maxValueLength {
+			return fmt.Errorf("%s value too long: %d bytes exceeds limit of %d", av.Type, len(v), maxValueLength)
+		}
+	case string:
+		if len(v) > maxValueLength {
+			return fmt.Errorf("%s value too long: %d bytes exceeds limit of %d", av.Type, len(v), maxValueLength)
+		}
+	}
+
+	return nil
+}
+
 type typeError struct {
 	exp interface{}
 	got interface{}
@@ -131,135 +610,345 @@ func (ate typeError) Error() string {
 
 // Serialize serializes the value into raw bytes. Only works on valid supported types.
 func (av *Value) Serialize() ([]byte, error) {
+	data, err := av.SerializeAppend(nil)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Hash returns a content address of the serialized value, using the raw codec
+// and Filecoin's default hash function. Equal values have equal hashes.
+func (av *Value) Hash() (cid.Cid, error) {
+	data, err := av.Serialize()
+	if err != nil {
+		return cid.Cid{}, err
+	}
+
+	return cid.V1Builder{Codec: cid.Raw, MhType: types.DefaultHashFunction}.Sum(data)
+}
+
+// SerializeAppend appends the serialized value to dst and returns the extended
+// buffer, which lets callers reuse a single buffer across many values. On
+// error dst is returned unchanged.
+func (av *Value) SerializeAppend(dst []byte) ([]byte, error) {
+	if av == nil {
+		return dst, ErrNilValue
+	}
+
 	switch av.Type {
 	case Invalid:
-		return nil, ErrInvalidType
+		return dst, ErrInvalidType
 	case Address:
 		addr, ok := av.Val.(address.Address)
 		if !ok {
-			return nil, &typeError{address.Address{}, av.Val}
+			return dst, &typeError{address.Address{}, av.Val}
 		}
-		return addr.Bytes(), nil
+		return append(dst, addr[:]...), nil
 	case AttoFIL:
 		ba, ok := av.Val.(*types.AttoFIL)
 		if !ok {
-			return nil, &typeError{types.AttoFIL{}, av.Val}
+			return dst, &typeError{types.AttoFIL{}, av.Val}
+		}
+		if ba.IsNegative() {
+			return dst, fmt.Errorf("token amount cannot be negative: %s", ba)
 		}
-		return ba.Bytes(), nil
+		return appendEncoding(dst, ba.Bytes()), nil
 	case BytesAmount:
 		ba, ok := av.Val.(*types.BytesAmount)
 		if !ok {
-			return nil, &typeError{types.BytesAmount{}, av.Val}
+			return dst, &typeError{types.BytesAmount{}, av.Val}
 		}
-		return ba.Bytes(), nil
+		return appendEncoding(dst, ba.Bytes()), nil
 	case ChannelID:
 		ba, ok := av.Val.(*types.ChannelID)
 		if !ok {
-			return nil, &typeError{types.ChannelID{}, av.Val}
+			return dst, &typeError{types.ChannelID{}, av.Val}
 		}
-		return ba.Bytes(), nil
+		return appendEncoding(dst, ba.Bytes()), nil
 	case BlockHeight:
 		ba, ok := av.Val.(*types.BlockHeight)
 		if !ok {
-			return nil, &typeError{types.BlockHeight{}, av.Val}
+			return dst, &typeError{types.BlockHeight{}, av.Val}
 		}
 		if ba == nil {
-			return nil, nil
+			return dst, fmt.Errorf("nil %s value", av.Type)
 		}
-		return ba.Bytes(), nil
+		h := ba.AsBigInt()
+		if h.Sign() < 0 || !h.IsUint64() {
+			return dst, fmt.Errorf("block height out of range: %s", h)
+		}
+
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], h.Uint64())
+		return append(dst, buf[:]...), nil
 	case Integer:
 		intgr, ok := av.Val.(*big.Int)
 		if !ok {
-			return nil, &typeError{&big.Int{}, av.Val}
+			return dst, &typeError{&big.Int{}, av.Val}
 		}
-		return intgr.Bytes(), nil
+		return appendEncoding(dst, serializeInteger(intgr)), nil
 	case Bytes:
 		b, ok := av.Val.([]byte)
 		if !ok {
-			return nil, &typeError{[]byte{}, av.Val}
+			return dst, &typeError{[]byte{}, av.Val}
 		}
-		return b, nil
+		if dst == nil {
+			// the encoding is never nil, even for nil or empty values
+			dst = make([]byte, 0, len(b))
+		}
+		return append(dst, b...), nil
 	case String:
 		s, ok := av.Val.(string)
 		if !ok {
-			return nil, &typeError{"", av.Val}
+			return dst, &typeError{"", av.Val}
 		}
-
-		return []byte(s), nil
+		if dst == nil {
+			dst = make([]byte, 0, len(s))
+		}
+		return append(dst, s...), nil
 	case UintArray:
 		arr, ok := av.Val.([]uint64)
 		if !ok {
-			return nil, &typeError{[]uint64{}, av.Val}
+			return dst, &typeError{[]uint64{}, av.Val}
 		}
 
-		return cbor.DumpObject(arr)
+		data, err := cbor.DumpObject(arr)
+		if err != nil {
+			return dst, err
+		}
+		return appendEncoding(dst, data), nil
 	case PeerID:
 		pid, ok := av.Val.(peer.ID)
 		if !ok {
-			return nil, &typeError{peer.ID(""), av.Val}
+			return dst, &typeError{peer.ID(""), av.Val}
 		}
 
-		return []byte(pid), nil
+		return appendEncoding(dst, []byte(pid)), nil
 	case SectorID:
 		n, ok := av.Val.(uint64)
 		if !ok {
-			return nil, &typeError{0, av.Val}
+			return dst, &typeError{0, av.Val}
 		}
 
-		return leb128.FromUInt64(n), nil
+		return appendEncoding(dst, leb128.FromUInt64(n)), nil
 	case CommitmentsMap:
 		m, ok := av.Val.(map[string]types.Commitments)
 		if !ok {
-			return nil, &typeError{map[string]types.Commitments{}, av.Val}
+			return dst, &typeError{map[string]types.Commitments{}, av.Val}
+		}
+
+		data, err := cbor.DumpObject(m)
+		if err != nil {
+			return dst, err
+		}
+		return appendEncoding(dst, data), nil
+	case Boolean:
+		b, ok := av.Val.(bool)
+		if !ok {
+			return dst, &typeError{false, av.Val}
 		}
 
-		return cbor.DumpObject(m)
+		if b {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case Uint64:
+		n, ok := av.Val.(uint64)
+		if !ok {
+			return dst, &typeError{uint64(0), av.Val}
+		}
+
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], n)
+		return append(dst, buf[:]...), nil
+	case Cid:
+		c, ok := av.Val.(cid.Cid)
+		if !ok {
+			return dst, &typeError{cid.Cid{}, av.Val}
+		}
+		if !c.Defined() {
+			return dst, fmt.Errorf("cannot serialize undefined cid")
+		}
+
+		return appendEncoding(dst, c.Bytes()), nil
+	case Int256:
+		intgr, ok := av.Val.(*big.Int)
+		if !ok {
+			return dst, &typeError{&big.Int{}, av.Val}
+		}
+		if err := checkInt256(intgr); err != nil {
+			return dst, err
+		}
+
+		var buf [32]byte
+		b := intgr.Bytes()
+		copy(buf[len(buf)-len(b):], b)
+		return append(dst, buf[:]...), nil
+	case Rational:
+		rat, ok := av.Val.(*big.Rat)
+		if !ok {
+			return dst, &typeError{&big.Rat{}, av.Val}
+		}
+
+		dst = appendSegment(dst, serializeInteger(rat.Num()))
+		return appendSegment(dst, rat.Denom().Bytes()), nil
+	case Float64:
+		f, ok := av.Val.(float64)
+		if !ok {
+			return dst, &typeError{float64(0), av.Val}
+		}
+
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], math.Float64bits(f))
+		return append(dst, buf[:]...), nil
+	case Int64:
+		n, ok := av.Val.(int64)
+		if !ok {
+			return dst, &typeError{int64(0), av.Val}
+		}
+
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(n))
+		return append(dst, buf[:]...), nil
+	case AddressSet:
+		addrs, ok := av.Val.([]address.Address)
+		if !ok {
+			return dst, &typeError{[]address.Address{}, av.Val}
+		}
+
+		set := normalizeAddressSet(addrs)
+		if dst == nil {
+			dst = make([]byte, 0, len(set)*address.Length)
+		}
+		for _, addr := range set {
+			dst = append(dst, addr[:]...)
+		}
+		return dst, nil
 	default:
-		return nil, fmt.Errorf("unrecognized Type: %d", av.Type)
+		codec, err := customCodec(av.Type)
+		if err != nil {
+			return dst, err
+		}
+
+		data, err := codec.Encode(av.Val)
+		if err != nil {
+			return dst, err
+		}
+		return appendEncoding(dst, data), nil
 	}
 }
 
+// appendEncoding appends a freshly allocated encoding to dst. For a nil dst,
+// which is what Serialize passes, the encoding is returned as is to save a
+// copy.
+func appendEncoding(dst, data []byte) []byte {
+	if dst == nil {
+		return data
+	}
+	return append(dst, data...)
+}
+
+// UnsupportedTypeError is returned by ToValues when one of the given go values
+// has no ABI type.
+type UnsupportedTypeError struct {
+	// Value is the offending value.
+	Value interface{}
+	// Index is the position of the value in the input slice.
+	Index int
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported type: %T", e.Value)
+}
+
 // ToValues converts from a slice of go abi-compatible values to abi values.
-// empty slices are normalized to nil
+// empty slices are normalized to nil. Nil pointers are rejected.
 func ToValues(i []interface{}) ([]*Value, error) {
 	if len(i) == 0 {
 		return nil, nil
 	}
 
 	out := make([]*Value, 0, len(i))
-	for _, v := range i {
-		switch v := v.(type) {
-		case address.Address:
-			out = append(out, &Value{Type: Address, Val: v})
-		case *types.AttoFIL:
-			out = append(out, &Value{Type: AttoFIL, Val: v})
-		case *types.BytesAmount:
-			out = append(out, &Value{Type: BytesAmount, Val: v})
-		case *types.ChannelID:
-			out = append(out, &Value{Type: ChannelID, Val: v})
-		case *types.BlockHeight:
-			out = append(out, &Value{Type: BlockHeight, Val: v})
-		case *big.Int:
-			out = append(out, &Value{Type: Integer, Val: v})
-		case []byte:
-			out = append(out, &Value{Type: Bytes, Val: v})
-		case string:
-			out = append(out, &Value{Type: String, Val: v})
-		case []uint64:
-			out = append(out, &Value{Type: UintArray, Val: v})
-		case peer.ID:
-			out = append(out, &Value{Type: PeerID, Val: v})
-		case uint64:
-			out = append(out, &Value{Type: SectorID, Val: v})
-		case map[string]types.Commitments:
-			out = append(out, &Value{Type: CommitmentsMap, Val: v})
-		default:
-			return nil, fmt.Errorf("unsupported type: %T", v)
+	for idx, v := range i {
+		t, ok := TypeOf(v)
+		if !ok {
+			return nil, &UnsupportedTypeError{Value: v, Index: idx}
+		}
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return nil, fmt.Errorf("parameter %d: cannot use nil %T", idx, v)
 		}
+		out = append(out, &Value{Type: t, Val: v})
 	}
 	return out, nil
 }
 
+// ToValuesRestricted works like ToValues, but errors if the type of any value
+// is not in allowed.
+func ToValuesRestricted(i []interface{}, allowed []Type) ([]*Value, error) {
+	vals, err := ToValues(i)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, v := range vals {
+		ok := false
+		for _, t := range allowed {
+			if v.Type == t {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("parameter %d: type %s is not allowed", idx, v.Type)
+		}
+	}
+	return vals, nil
+}
+
+// TypeOf returns the ABI type ToValues uses for the given go value, and
+// whether the value is supported at all.
+func TypeOf(v interface{}) (Type, bool) {
+	switch v.(type) {
+	case address.Address:
+		return Address, true
+	case *types.AttoFIL:
+		return AttoFIL, true
+	case *types.BytesAmount:
+		return BytesAmount, true
+	case *types.ChannelID:
+		return ChannelID, true
+	case *types.BlockHeight:
+		return BlockHeight, true
+	case *big.Int:
+		return Integer, true
+	case []byte:
+		return Bytes, true
+	case string:
+		return String, true
+	case []uint64:
+		return UintArray, true
+	case peer.ID:
+		return PeerID, true
+	case uint64:
+		return SectorID, true
+	case map[string]types.Commitments:
+		return CommitmentsMap, true
+	case bool:
+		return Boolean, true
+	case cid.Cid:
+		return Cid, true
+	case *big.Rat:
+		return Rational, true
+	case int64:
+		return Int64, true
+	case []address.Address:
+		return AddressSet, true
+	default:
+		return Invalid, false
+	}
+}
+
 // FromValues converts from a slice of abi values to the go type representation
 // of them. empty slices are normalized to nil
 func FromValues(vals []*Value) []interface{} {
@@ -274,93 +963,583 @@ func FromValues(vals []*Value) []interface{} {
 	return out
 }
 
+// FromValuesCopy works like FromValues, but returns deep copies of mutable
+// values like *big.Int and []byte, so that callers can modify them without
+// affecting vals.
+func FromValuesCopy(vals []*Value) []interface{} {
+	if len(vals) == 0 {
+		return nil
+	}
+
+	out := make([]interface{}, 0, len(vals))
+	for _, v := range vals {
+		out = append(out, v.Clone().Val)
+	}
+	return out
+}
+
 // Deserialize converts the given bytes to the requested type and returns an
-// ABI Value for it.
+// ABI Value for it. The returned Value never aliases data, so the caller is
+// free to reuse the buffer afterwards.
 func Deserialize(data []byte, t Type) (*Value, error) {
+	val, err := deserializeVal(data, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Value{Type: t, Val: val}, nil
+}
+
+// DeserializeInto works like Deserialize, but stores the result in av instead
+// of allocating a new Value. Together with GetValue and PutValue it avoids an
+// allocation per value in decode loops. av is left untouched on error.
+func DeserializeInto(data []byte, t Type, av *Value) error {
+	if av == nil {
+		return ErrNilValue
+	}
+
+	val, err := deserializeVal(data, t)
+	if err != nil {
+		return err
+	}
+
+	av.Type = t
+	av.Val = val
+	return nil
+}
+
+func deserializeVal(data []byte, t Type) (interface{}, error) {
 	switch t {
 	case Address:
+		if err := checkFixedLen("Address", data, address.Length); err != nil {
+			return nil, err
+		}
+
 		addr, err := address.NewFromBytes(data)
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrap(err, "invalid address encoding")
 		}
 
-		return &Value{
-			Type: t,
-			Val:  addr,
-		}, nil
+		return addr, nil
 	case AttoFIL:
-		return &Value{
-			Type: t,
-			Val:  types.NewAttoFILFromBytes(data),
-		}, nil
+		return types.NewAttoFILFromBytes(data), nil
 	case Bytes:
-		return &Value{
-			Type: t,
-			Val:  data,
-		}, nil
+		b := make([]byte, len(data))
+		copy(b, data)
+
+		return b, nil
 	case BytesAmount:
-		return &Value{
-			Type: t,
-			Val:  types.NewBytesAmountFromBytes(data),
-		}, nil
+		return types.NewBytesAmountFromBytes(data), nil
 	case ChannelID:
-		return &Value{
-			Type: t,
-			Val:  types.NewChannelIDFromBytes(data),
-		}, nil
+		return types.NewChannelIDFromBytes(data), nil
 	case BlockHeight:
-		return &Value{
-			Type: t,
-			Val:  types.NewBlockHeightFromBytes(data),
-		}, nil
+		if err := checkFixedLen("BlockHeight", data, 8); err != nil {
+			return nil, err
+		}
+
+		return types.NewBlockHeight(binary.BigEndian.Uint64(data)), nil
 	case Integer:
-		return &Value{
-			Type: t,
-			Val:  big.NewInt(0).SetBytes(data),
-		}, nil
+		intgr, err := deserializeInteger(data)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIntegerBits(intgr); err != nil {
+			return nil, err
+		}
+
+		return intgr, nil
 	case String:
-		return &Value{
-			Type: t,
-			Val:  string(data),
-		}, nil
+		return string(data), nil
 	case UintArray:
 		var arr []uint64
 		if err := cbor.DecodeInto(data, &arr); err != nil {
 			return nil, err
 		}
-		return &Value{
-			Type: t,
-			Val:  arr,
-		}, nil
+		return arr, nil
 	case PeerID:
 		id, err := peer.IDFromBytes(data)
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrap(err, "invalid peer id encoding")
 		}
 
-		return &Value{
-			Type: t,
-			Val:  id,
-		}, nil
+		return id, nil
 	case SectorID:
-		return &Value{
-			Type: t,
-			Val:  leb128.ToUInt64(data),
-		}, nil
+		return leb128.ToUInt64(data), nil
 
 	case CommitmentsMap:
 		var m map[string]types.Commitments
 		if err := cbor.DecodeInto(data, &m); err != nil {
 			return nil, err
 		}
+		return m, nil
+	case Boolean:
+		if err := checkFixedLen("Boolean", data, 1); err != nil {
+			return nil, err
+		}
+
+		switch data[0] {
+		case 0:
+			return false, nil
+		case 1:
+			return true, nil
+		default:
+			return nil, fmt.Errorf("invalid boolean encoding: %#x", data[0])
+		}
+	case Uint64:
+		if err := checkFixedLen("Uint64", data, 8); err != nil {
+			return nil, err
+		}
+
+		return binary.BigEndian.Uint64(data), nil
+	case Cid:
+		if len(data) == 0 {
+			return nil, fmt.Errorf("invalid cid encoding: empty input")
+		}
+
+		c, err := cid.Cast(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cid encoding")
+		}
+		// the version and codec must survive unchanged, otherwise re-encoding
+		// the value would change its bytes
+		if !bytes.Equal(c.Bytes(), data) {
+			return nil, fmt.Errorf("invalid cid encoding: does not round trip")
+		}
+
+		return c, nil
+	case Int256:
+		if err := checkFixedLen("Int256", data, 32); err != nil {
+			return nil, err
+		}
+
+		return big.NewInt(0).SetBytes(data), nil
+	case Rational:
+		segments, err := readSegments(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid rational encoding")
+		}
+		if len(segments) != 2 {
+			return nil, fmt.Errorf("invalid rational encoding: expected 2 segments, got %d", len(segments))
+		}
+
+		num, err := deserializeInteger(segments[0])
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid rational encoding")
+		}
+		denom := big.NewInt(0).SetBytes(segments[1])
+		if denom.Sign() == 0 {
+			return nil, fmt.Errorf("invalid rational encoding: zero denominator")
+		}
+
+		return new(big.Rat).SetFrac(num, denom), nil
+	case Float64:
+		if err := checkFixedLen("Float64", data, 8); err != nil {
+			return nil, err
+		}
+
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	case Int64:
+		if err := checkFixedLen("Int64", data, 8); err != nil {
+			return nil, err
+		}
+
+		return int64(binary.BigEndian.Uint64(data)), nil
+	case AddressSet:
+		if len(data)%address.Length != 0 {
+			return nil, fmt.Errorf("invalid address set encoding: length %d is not a multiple of %d", len(data), address.Length)
+		}
+
+		addrs := make([]address.Address, 0, len(data)/address.Length)
+		for i := 0; i < len(data); i += address.Length {
+			addr, err := address.NewFromBytes(data[i : i+address.Length])
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid address set encoding")
+			}
+
+			if n := len(addrs); n > 0 {
+				switch bytes.Compare(addrs[n-1][:], addr[:]) {
+				case 0:
+					return nil, fmt.Errorf("invalid address set encoding: duplicate address %s", addr)
+				case 1:
+					return nil, fmt.Errorf("invalid address set encoding: addresses out of order")
+				}
+			}
+			addrs = append(addrs, addr)
+		}
+
+		return addrs, nil
+	case Invalid:
+		return nil, ErrInvalidType
+	default:
+		codec, err := customCodec(t)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := codec.Decode(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return val, nil
+	}
+}
+
+// MustDeserialize works like Deserialize, but panics on error. It is meant for
+// tests and package level variables initialized from known good input, never
+// use it on untrusted data.
+func MustDeserialize(data []byte, t Type) *Value {
+	v, err := Deserialize(data, t)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// DeserializeSafe works like Deserialize, but turns any panic in the decoding
+// logic, including that of registered codecs, into an error. It never panics,
+// whatever the input. Since decoding LEB128 takes time quadratic in the input
+// length, it also bounds AttoFIL, BytesAmount and ChannelID encodings by
+// MaxIntegerBits, which Deserialize doesn't do.
+func DeserializeSafe(data []byte, t Type) (av *Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			av = nil
+			err = fmt.Errorf("panic decoding %s: %v", t, r)
+		}
+	}()
+
+	switch t {
+	case AttoFIL, BytesAmount, ChannelID:
+		if err := checkLeb128Length(data); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s encoding", t)
+		}
+	}
+
+	return Deserialize(data, t)
+}
+
+// DeserializeAt works like Deserialize, but annotates errors with the position
+// of the value in a parameter list and the type it was decoded as.
+func DeserializeAt(data []byte, t Type, index int) (*Value, error) {
+	v, err := Deserialize(data, t)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parameter %d: failed to decode %s", index, t)
+	}
+
+	return v, nil
+}
+
+// Canonicalize decodes data as the given type and encodes it again, returning
+// the canonical encoding of the value. Encodings produced by Serialize are
+// always canonical, so they are returned unchanged.
+func Canonicalize(data []byte, t Type) ([]byte, error) {
+	v, err := Deserialize(data, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Serialize()
+}
+
+// DeserializeCanonical works like Deserialize, but only accepts the canonical
+// encoding of a value, i.e. the one Serialize produces. It rejects Integer
+// encodings with leading zero bytes or a negative zero, so that decoding and
+// encoding again is always the identity.
+func DeserializeCanonical(data []byte, t Type) (*Value, error) {
+	if t == Integer && len(data) > 1 && data[1] == 0 {
+		return nil, fmt.Errorf("non canonical integer encoding: leading zero byte")
+	}
+	if t == Integer && len(data) == 1 && data[0] == integerSignNegative {
+		return nil, fmt.Errorf("non canonical integer encoding: negative zero")
+	}
+
+	v, err := Deserialize(data, t)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, err := v.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(data, canonical) {
+		return nil, fmt.Errorf("non canonical %s encoding", t)
+	}
+
+	return v, nil
+}
+
+// DeserializeStrict works like DeserializeCanonical, and additionally requires
+// String values to be valid UTF-8. Use it for strings that end up in JSON or
+// logs.
+func DeserializeStrict(data []byte, t Type) (*Value, error) {
+	return DeserializeWith(data, t, DecodeOptions{Canonical: true, ValidateUTF8: true})
+}
+
+// DecodeOptions selects the checks DeserializeWith applies on top of
+// Deserialize. The zero value applies none.
+type DecodeOptions struct {
+	// Canonical rejects encodings that differ from what Serialize produces,
+	// see DeserializeCanonical.
+	Canonical bool
+	// ValidateUTF8 requires String values to be valid UTF-8.
+	ValidateUTF8 bool
+	// MaxBytes rejects encodings longer than this many bytes, if positive.
+	MaxBytes int
+	// RejectTrailing rejects encodings with bytes after the value that the
+	// decoder would otherwise ignore. Non canonical encodings of a value are
+	// not trailing data, Canonical rejects those.
+	RejectTrailing bool
+}
+
+// DeserializeWith works like Deserialize, with the additional checks selected
+// by opts.
+func DeserializeWith(data []byte, t Type, opts DecodeOptions) (*Value, error) {
+	if opts.MaxBytes > 0 && len(data) > opts.MaxBytes {
+		return nil, fmt.Errorf("%s encoding too long: %d bytes exceeds limit of %d", t, len(data), opts.MaxBytes)
+	}
+	if opts.ValidateUTF8 && t == String && !utf8.Valid(data) {
+		return nil, fmt.Errorf("invalid string encoding: not valid utf-8")
+	}
+
+	var v *Value
+	var err error
+	if opts.Canonical {
+		v, err = DeserializeCanonical(data, t)
+	} else {
+		v, err = Deserialize(data, t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.RejectTrailing {
+		n, err := encodedLength(data, t)
+		if err != nil {
+			return nil, err
+		}
+		if n < len(data) {
+			return nil, errors.Wrapf(ErrTrailingData, "%d bytes left after %s value", len(data)-n, t)
+		}
+	}
+
+	return v, nil
+}
+
+// encodedLength returns the length of the encoding of a value of type t at the
+// start of data. Only SectorID and the cbor based types are self delimiting,
+// the decoders of all other types consume their whole input.
+func encodedLength(data []byte, t Type) (int, error) {
+	switch t {
+	case SectorID:
+		for i, b := range data {
+			if b&0x80 == 0 {
+				return i + 1, nil
+			}
+		}
+		return len(data), nil
+	case UintArray, CommitmentsMap:
+		rest, err := skipCborItem(data)
+		if err != nil {
+			return 0, err
+		}
+		return len(data) - len(rest), nil
+	default:
+		return len(data), nil
+	}
+}
+
+// Signedness tells DeserializeStrictWithSign whether negative numbers are
+// acceptable for a parameter.
+type Signedness int
+
+const (
+	// Signed accepts negative and non-negative numbers.
+	Signed = Signedness(iota)
+	// Unsigned rejects negative numbers.
+	Unsigned
+)
+
+// DeserializeStrictWithSign works like DeserializeStrict, but for Unsigned
+// parameters it also rejects negative Integer, Rational and Int64 values.
+func DeserializeStrictWithSign(data []byte, t Type, sign Signedness) (*Value, error) {
+	v, err := DeserializeStrict(data, t)
+	if err != nil {
+		return nil, err
+	}
+	if sign != Unsigned {
+		return v, nil
+	}
+
+	var negative bool
+	switch n := v.Val.(type) {
+	case *big.Int:
+		negative = n.Sign() < 0
+	case *big.Rat:
+		negative = n.Sign() < 0
+	case int64:
+		negative = n < 0
+	}
+	if negative {
+		return nil, fmt.Errorf("expected a non-negative %s, got %s", t, v.Format())
+	}
+
+	return v, nil
+}
+
+// DeserializeNoCopy works like Deserialize, except that a Bytes Value aliases
+// data instead of holding a copy of it. The caller must guarantee that data is
+// not modified for as long as the returned Value is in use.
+func DeserializeNoCopy(data []byte, t Type) (*Value, error) {
+	if t == Bytes {
+		if data == nil {
+			data = []byte{}
+		}
+
 		return &Value{
 			Type: t,
-			Val:  m,
+			Val:  data,
 		}, nil
-	case Invalid:
-		return nil, ErrInvalidType
+	}
+
+	return Deserialize(data, t)
+}
+
+// DeserializeWithLimit works like Deserialize, but rejects Bytes and String
+// values that are longer than max bytes. Use it to bound the size of values
+// decoded from untrusted input.
+func DeserializeWithLimit(data []byte, t Type, max int) (*Value, error) {
+	if (t == Bytes || t == String) && len(data) > max {
+		return nil, fmt.Errorf("%s value exceeds size limit: limit is %d bytes, got %d", t, max, len(data))
+	}
+
+	return Deserialize(data, t)
+}
+
+// checkLeb128Length applies MaxIntegerBits to LEB128 encoded numbers. Decoding
+// them takes time quadratic in their length, so overlong inputs are rejected
+// before decoding.
+func checkLeb128Length(data []byte) error {
+	if MaxIntegerBits <= 0 {
+		return nil
+	}
+
+	// every byte carries 7 bits
+	if max := (MaxIntegerBits + 6) / 7; len(data) > max {
+		return fmt.Errorf("leb128 number too long: %d bytes exceeds limit of %d", len(data), max)
+	}
+	return nil
+}
+
+const (
+	integerSignPositive = 0x00
+	integerSignNegative = 0x01
+)
+
+func serializeInteger(intgr *big.Int) []byte {
+	// fast path for integers whose magnitude fits into a single byte
+	if intgr.IsInt64() {
+		switch n := intgr.Int64(); {
+		case n == 0:
+			return []byte{integerSignPositive}
+		case n > 0 && n <= math.MaxUint8:
+			return []byte{integerSignPositive, byte(n)}
+		case n < 0 && n >= -math.MaxUint8:
+			return []byte{integerSignNegative, byte(-n)}
+		}
+	}
+
+	return serializeIntegerGeneral(intgr)
+}
+
+func serializeIntegerGeneral(intgr *big.Int) []byte {
+	sign := byte(integerSignPositive)
+	if intgr.Sign() < 0 {
+		sign = integerSignNegative
+	}
+
+	return append([]byte{sign}, intgr.Bytes()...)
+}
+
+// checkFixedLen checks the length of the encoding of a fixed width type, so
+// that all of them report a wrong length the same way.
+func checkFixedLen(name string, data []byte, want int) error {
+	if len(data) == want {
+		return nil
+	}
+
+	unit := "bytes"
+	if want == 1 {
+		unit = "byte"
+	}
+	return fmt.Errorf("%s: expected %d %s, got %d", name, want, unit, len(data))
+}
+
+// normalizeAddressSet returns a sorted copy of the addresses with duplicates
+// removed, which is the order AddressSet values are encoded in.
+func normalizeAddressSet(addrs []address.Address) []address.Address {
+	sorted := append([]address.Address{}, addrs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+
+	out := sorted[:0]
+	for i, addr := range sorted {
+		if i > 0 && addr == sorted[i-1] {
+			continue
+		}
+		out = append(out, addr)
+	}
+	return out
+}
+
+// checkIntegerBits applies MaxIntegerBits to a decoded Integer.
+func checkIntegerBits(intgr *big.Int) error {
+	if MaxIntegerBits > 0 && intgr.BitLen() > MaxIntegerBits {
+		return fmt.Errorf("integer too large: %d bits exceeds limit of %d", intgr.BitLen(), MaxIntegerBits)
+	}
+	return nil
+}
+
+// checkInt256 checks that intgr can be encoded as an Int256.
+func checkInt256(intgr *big.Int) error {
+	if intgr.Sign() < 0 {
+		return fmt.Errorf("int256 cannot be negative: %s", intgr)
+	}
+	if intgr.BitLen() > 256 {
+		return fmt.Errorf("int256 overflow: %s does not fit into 256 bits", intgr)
+	}
+	return nil
+}
+
+func deserializeInteger(data []byte) (*big.Int, error) {
+	// fast path for integers whose magnitude fits into a single byte
+	if len(data) == 2 {
+		switch data[0] {
+		case integerSignPositive:
+			return big.NewInt(int64(data[1])), nil
+		case integerSignNegative:
+			return big.NewInt(-int64(data[1])), nil
+		}
+	}
+
+	return deserializeIntegerGeneral(data)
+}
+
+func deserializeIntegerGeneral(data []byte) (*big.Int, error) {
+	if len(data) == 0 {
+		return big.NewInt(0), nil
+	}
+
+	intgr := big.NewInt(0).SetBytes(data[1:])
+	switch data[0] {
+	case integerSignPositive:
+		return intgr, nil
+	case integerSignNegative:
+		return intgr.Neg(intgr), nil
 	default:
-		return nil, fmt.Errorf("unrecognized Type: %d", t)
+		return nil, fmt.Errorf("invalid integer encoding: unknown sign byte %#x", data[0])
 	}
 }
 
@@ -377,6 +1556,14 @@ var typeTable = map[Type]reflect.Type{
 	PeerID:         reflect.TypeOf(peer.ID("")),
 	SectorID:       reflect.TypeOf(uint64(0)),
 	CommitmentsMap: reflect.TypeOf(map[string]types.Commitments{}),
+	Boolean:        reflect.TypeOf(false),
+	Uint64:         reflect.TypeOf(uint64(0)),
+	Cid:            reflect.TypeOf(cid.Cid{}),
+	Int256:         reflect.TypeOf(&big.Int{}),
+	Rational:       reflect.TypeOf(&big.Rat{}),
+	Float64:        reflect.TypeOf(float64(0)),
+	Int64:          reflect.TypeOf(int64(0)),
+	AddressSet:     reflect.TypeOf([]address.Address{}),
 }
 
 // TypeMatches returns whether or not 'val' is the go type expected for the given ABI type
@@ -387,3 +1574,24 @@ func TypeMatches(t Type, val reflect.Type) bool {
 	}
 	return rt == val
 }
+
+// TypeAssignable is a looser TypeMatches: it also accepts defined types whose
+// values can be converted to the expected go type, like a named string type or
+// a pointer to a type defined as big.Int. Values of such types have to be
+// converted with reflect.Value.Convert before they can be serialized.
+func TypeAssignable(t Type, val reflect.Type) bool {
+	rt, ok := typeTable[t]
+	if !ok || val == nil {
+		return false
+	}
+	return val.Kind() == rt.Kind() && val.ConvertibleTo(rt)
+}
+
+// ValueMatches returns whether or not the dynamic type of 'v' is the go type
+// expected for the given ABI type. A nil interface never matches.
+func ValueMatches(t Type, v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	return TypeMatches(t, reflect.TypeOf(v))
+}
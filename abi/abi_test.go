@@ -0,0 +1,1153 @@
+package abi
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"gx/ipfs/QmR8BauakNcBa3RbE4nbQu76PDiJgoQgz8AJdhJuiU4TAw/go-cid"
+	"gx/ipfs/QmY5Grm8pJdiSSVsYxx4uNRgweY72EmYwuSDbRnbFok3iY/go-libp2p-peer"
+	mh "gx/ipfs/QmerPMzPk1mJVowm8KgmoknWa4yCYvvugMPsgWmDNUvDLW/go-multihash"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBooleanRoundTrip(t *testing.T) {
+	for _, b := range []bool{true, false} {
+		assert := assert.New(t)
+
+		data, err := (&Value{Type: Boolean, Val: b}).Serialize()
+		assert.NoError(err)
+		assert.Len(data, 1)
+
+		v, err := Deserialize(data, Boolean)
+		assert.NoError(err)
+		assert.Equal(b, v.Val)
+	}
+}
+
+func TestBooleanDeserializeFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Deserialize(nil, Boolean)
+	assert.EqualError(err, "Boolean: expected 1 byte, got 0")
+
+	_, err = Deserialize([]byte{}, Boolean)
+	assert.EqualError(err, "Boolean: expected 1 byte, got 0")
+
+	_, err = Deserialize([]byte{0, 1}, Boolean)
+	assert.EqualError(err, "Boolean: expected 1 byte, got 2")
+
+	_, err = Deserialize([]byte{2}, Boolean)
+	assert.EqualError(err, "invalid boolean encoding: 0x2")
+}
+
+func TestUint64RoundTrip(t *testing.T) {
+	for _, n := range []uint64{0, 1, math.MaxUint64} {
+		assert := assert.New(t)
+
+		data, err := (&Value{Type: Uint64, Val: n}).Serialize()
+		assert.NoError(err)
+		assert.Len(data, 8)
+
+		v, err := Deserialize(data, Uint64)
+		assert.NoError(err)
+		assert.Equal(n, v.Val)
+	}
+}
+
+func TestUint64DeserializeFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Deserialize(nil, Uint64)
+	assert.EqualError(err, "Uint64: expected 8 bytes, got 0")
+
+	_, err = Deserialize(make([]byte, 9), Uint64)
+	assert.EqualError(err, "Uint64: expected 8 bytes, got 9")
+}
+
+func TestCheckFixedLen(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(checkFixedLen("Uint64", make([]byte, 8), 8))
+	assert.EqualError(checkFixedLen("Uint64", make([]byte, 3), 8), "Uint64: expected 8 bytes, got 3")
+	assert.EqualError(checkFixedLen("Uint64", make([]byte, 12), 8), "Uint64: expected 8 bytes, got 12")
+	assert.EqualError(checkFixedLen("Boolean", nil, 1), "Boolean: expected 1 byte, got 0")
+}
+
+func TestValueString(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := address.NewForTestGetter()()
+
+	assert.Equal("<invalid>", (&Value{}).String())
+	assert.Equal("<unknown type>", (&Value{Type: Type(9999)}).String())
+	assert.Equal("address.Address("+addr.String()+")", (&Value{Type: Address, Val: addr}).String())
+	assert.Equal("*big.Int(-1234)", (&Value{Type: Integer, Val: big.NewInt(-1234)}).String())
+	assert.Equal("[]byte(deadbeef)", (&Value{Type: Bytes, Val: []byte{0xde, 0xad, 0xbe, 0xef}}).String())
+	assert.Equal(`string("mr \"rogers\"")`, (&Value{Type: String, Val: `mr "rogers"`}).String())
+	assert.Equal("bool(true)", (&Value{Type: Boolean, Val: true}).String())
+	assert.Equal("uint64(42)", (&Value{Type: SectorID, Val: uint64(42)}).String())
+
+	// mismatched values are rendered instead of panicking
+	assert.Equal("*big.Int(foo)", (&Value{Type: Integer, Val: "foo"}).String())
+}
+
+func TestValueRedacted(t *testing.T) {
+	assert := assert.New(t)
+
+	secret := bytes.Repeat([]byte{0xde, 0xad, 0xbe, 0xef}, 16)
+	out := (&Value{Type: Bytes, Val: secret}).Redacted()
+	assert.Contains(out, "len=64")
+	assert.Contains(out, "sha256=")
+	assert.NotContains(out, "deadbeef")
+	assert.Equal(out, (&Value{Type: Bytes, Val: append([]byte{}, secret...)}).Redacted())
+
+	// everything else is rendered in full
+	assert.Equal("*big.Int(-1234)", (&Value{Type: Integer, Val: big.NewInt(-1234)}).Redacted())
+	assert.Equal(`string("foo")`, (&Value{Type: String, Val: "foo"}).Redacted())
+	assert.Equal("<nil>", (*Value)(nil).Redacted())
+}
+
+func TestValueEquals(t *testing.T) {
+	assert := assert.New(t)
+
+	addrGetter := address.NewForTestGetter()
+	addr := addrGetter()
+
+	// numerically equal big.Ints with different internal representations
+	a := &Value{Type: Integer, Val: big.NewInt(0)}
+	b := &Value{Type: Integer, Val: new(big.Int).SetBytes([]byte{0, 0})}
+	assert.True(a.Equals(b))
+
+	a = &Value{Type: Integer, Val: big.NewInt(300)}
+	b = &Value{Type: Integer, Val: new(big.Int).Sub(big.NewInt(301), big.NewInt(1))}
+	assert.True(a.Equals(b))
+	assert.False(a.Equals(&Value{Type: Integer, Val: big.NewInt(301)}))
+
+	assert.True((&Value{Type: Bytes, Val: []byte{}}).Equals(&Value{Type: Bytes, Val: []byte(nil)}))
+	assert.False((&Value{Type: Bytes, Val: []byte("a")}).Equals(&Value{Type: Bytes, Val: []byte("b")}))
+
+	assert.True((&Value{Type: Address, Val: addr}).Equals(&Value{Type: Address, Val: addr}))
+	assert.False((&Value{Type: Address, Val: addr}).Equals(&Value{Type: Address, Val: addrGetter()}))
+
+	assert.True((&Value{Type: String, Val: "foo"}).Equals(&Value{Type: String, Val: "foo"}))
+	assert.False((&Value{Type: String, Val: "foo"}).Equals(&Value{Type: String, Val: "bar"}))
+
+	// same go value, different abi types
+	assert.False((&Value{Type: SectorID, Val: uint64(1)}).Equals(&Value{Type: Uint64, Val: uint64(1)}))
+
+	assert.True((&Value{}).Equals(&Value{}))
+	assert.True((*Value)(nil).Equals(nil))
+	assert.False((&Value{}).Equals(nil))
+}
+
+func TestValueConstantTimeEquals(t *testing.T) {
+	assert := assert.New(t)
+
+	mac := &Value{Type: Bytes, Val: []byte{0xde, 0xad, 0xbe, 0xef}}
+	for _, other := range []*Value{
+		{Type: Bytes, Val: []byte{0xde, 0xad, 0xbe, 0xef}},
+		{Type: Bytes, Val: []byte{0xde, 0xad, 0xbe, 0xee}},
+		{Type: Bytes, Val: []byte{0xde, 0xad}},
+		{Type: Bytes, Val: []byte(nil)},
+		{Type: String, Val: "\xde\xad\xbe\xef"},
+		nil,
+	} {
+		assert.Equal(mac.Equals(other), mac.ConstantTimeEquals(other), "%s vs %s", mac, other)
+	}
+
+	// empty and nil byte slices are equal, just like with Equals
+	assert.True((&Value{Type: Bytes, Val: []byte{}}).ConstantTimeEquals(&Value{Type: Bytes, Val: []byte(nil)}))
+
+	// other types fall back to Equals
+	assert.True((&Value{Type: String, Val: "foo"}).ConstantTimeEquals(&Value{Type: String, Val: "foo"}))
+	assert.True((*Value)(nil).ConstantTimeEquals(nil))
+}
+
+func TestIntegerSignRoundTrip(t *testing.T) {
+	largeNeg, ok := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	assert.True(t, ok)
+
+	for _, n := range []*big.Int{big.NewInt(-1), largeNeg, big.NewInt(0), big.NewInt(1)} {
+		assert := assert.New(t)
+
+		data, err := (&Value{Type: Integer, Val: n}).Serialize()
+		assert.NoError(err)
+
+		v, err := Deserialize(data, Integer)
+		assert.NoError(err)
+		assert.Equal(0, n.Cmp(v.Val.(*big.Int)), "expected %s, got %s", n, v.Val)
+	}
+}
+
+func TestIntegerEncoding(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := (&Value{Type: Integer, Val: big.NewInt(258)}).Serialize()
+	assert.NoError(err)
+	assert.Equal([]byte{0x00, 0x01, 0x02}, data)
+
+	data, err = (&Value{Type: Integer, Val: big.NewInt(-258)}).Serialize()
+	assert.NoError(err)
+	assert.Equal([]byte{0x01, 0x01, 0x02}, data)
+
+	// both the empty and the single zero byte encoding decode to zero
+	for _, zero := range [][]byte{nil, {}, {0x00}} {
+		v, err := Deserialize(zero, Integer)
+		assert.NoError(err)
+		assert.Equal(0, v.Val.(*big.Int).Sign())
+	}
+
+	_, err = DeserializeCanonical(nil, Integer)
+	assert.EqualError(err, "non canonical *big.Int encoding")
+
+	_, err = Deserialize([]byte{0x02, 0x01}, Integer)
+	assert.EqualError(err, "invalid integer encoding: unknown sign byte 0x2")
+}
+
+func TestIntegerBitLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(MaxIntegerBits)), big.NewInt(1))
+	for _, n := range []*big.Int{max, new(big.Int).Neg(max)} {
+		data, err := (&Value{Type: Integer, Val: n}).Serialize()
+		assert.NoError(err)
+
+		v, err := Deserialize(data, Integer)
+		assert.NoError(err)
+		assert.Equal(0, n.Cmp(v.Val.(*big.Int)))
+	}
+
+	tooLarge := new(big.Int).Lsh(big.NewInt(1), uint(MaxIntegerBits))
+	data, err := (&Value{Type: Integer, Val: tooLarge}).Serialize()
+	assert.NoError(err)
+
+	_, err = Deserialize(data, Integer)
+	assert.EqualError(err, "integer too large: 2049 bits exceeds limit of 2048")
+}
+
+func TestLeb128LengthLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(MaxIntegerBits)), big.NewInt(1))
+	amount := types.NewAttoFIL(max)
+	data, err := (&Value{Type: AttoFIL, Val: amount}).Serialize()
+	assert.NoError(err)
+	assert.Len(data, 293)
+
+	v, err := Deserialize(data, AttoFIL)
+	assert.NoError(err)
+	assert.True(amount.Equal(v.Val.(*types.AttoFIL)))
+
+	tooLong := bytes.Repeat([]byte{0xff}, 294)
+	for _, typ := range []Type{AttoFIL, BytesAmount, ChannelID} {
+		_, err := DeserializeSafe(tooLong, typ)
+		assert.Error(err)
+		assert.Contains(err.Error(), "leb128 number too long: 294 bytes exceeds limit of 293")
+
+		// the consensus decoding is unchanged
+		_, err = Deserialize(tooLong, typ)
+		assert.NoError(err)
+	}
+}
+
+func TestCidRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	c, err := cid.NewPrefixV1(cid.DagCBOR, mh.SHA2_256).Sum([]byte("piece"))
+	require.NoError(err)
+
+	vals, err := ToValues([]interface{}{c})
+	require.NoError(err)
+	assert.Equal(Cid, vals[0].Type)
+
+	data, err := vals[0].Serialize()
+	require.NoError(err)
+	assert.Equal(c.Bytes(), data)
+
+	v, err := Deserialize(data, Cid)
+	require.NoError(err)
+	assert.True(c.Equals(v.Val.(cid.Cid)))
+}
+
+func TestCidVersionsRoundTrip(t *testing.T) {
+	hash, err := mh.Sum([]byte("piece"), mh.SHA2_256, -1)
+	require.NoError(t, err)
+
+	for name, c := range map[string]cid.Cid{
+		"v0 dag-pb": cid.NewCidV0(hash),
+		"v1 raw":    cid.NewCidV1(cid.Raw, hash),
+		"v1 dag-pb": cid.NewCidV1(cid.DagProtobuf, hash),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			v, err := Deserialize(c.Bytes(), Cid)
+			require.NoError(err)
+
+			out := v.Val.(cid.Cid)
+			assert.Equal(c.Prefix(), out.Prefix())
+
+			data, err := v.Serialize()
+			require.NoError(err)
+			assert.Equal(c.Bytes(), data)
+		})
+	}
+}
+
+func TestCidFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := cid.NewPrefixV1(cid.DagCBOR, mh.SHA2_256).Sum([]byte("piece"))
+	assert.NoError(err)
+
+	_, err = (&Value{Type: Cid, Val: cid.Undef}).Serialize()
+	assert.EqualError(err, "cannot serialize undefined cid")
+
+	_, err = Deserialize(nil, Cid)
+	assert.EqualError(err, "invalid cid encoding: empty input")
+
+	_, err = Deserialize(c.Bytes()[:len(c.Bytes())-3], Cid)
+	assert.Error(err)
+
+	_, err = Deserialize(append(c.Bytes(), 0), Cid)
+	assert.Error(err)
+}
+
+func TestTypeFromString(t *testing.T) {
+	assert := assert.New(t)
+
+	for name, expected := range map[string]Type{
+		"address.Address":        Address,
+		"*types.AttoFIL":         AttoFIL,
+		"*types.BytesAmount":     BytesAmount,
+		"*types.ChannelID":       ChannelID,
+		"*types.BlockHeight":     BlockHeight,
+		"*big.Int":               Integer,
+		"[]byte":                 Bytes,
+		"string":                 String,
+		"[]uint64":               UintArray,
+		"peer.ID":                PeerID,
+		"uint64":                 SectorID,
+		"map[string]Commitments": CommitmentsMap,
+		"bool":                   Boolean,
+		"uint64be":               Uint64,
+		"cid.Cid":                Cid,
+		"int256":                 Int256,
+		"*big.Rat":               Rational,
+		"float64":                Float64,
+		"int64":                  Int64,
+		"[]address.Address":      AddressSet,
+	} {
+		typ, err := TypeFromString(name)
+		assert.NoError(err)
+		assert.Equal(expected, typ)
+	}
+
+	// every known type survives the round trip through its name
+	for typ := range typeTable {
+		out, err := TypeFromString(typ.String())
+		assert.NoError(err)
+		assert.Equal(typ, out)
+	}
+
+	_, err := TypeFromString("<invalid>")
+	assert.Equal(ErrInvalidType, err)
+
+	_, err = TypeFromString("complex128")
+	assert.EqualError(err, `unknown type: "complex128"`)
+}
+
+func TestInt256RoundTrip(t *testing.T) {
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	for _, n := range []*big.Int{big.NewInt(0), big.NewInt(1), max} {
+		assert := assert.New(t)
+
+		data, err := (&Value{Type: Int256, Val: n}).Serialize()
+		assert.NoError(err)
+		assert.Len(data, 32)
+
+		v, err := Deserialize(data, Int256)
+		assert.NoError(err)
+		assert.Equal(0, n.Cmp(v.Val.(*big.Int)))
+	}
+}
+
+func TestInt256Failures(t *testing.T) {
+	assert := assert.New(t)
+
+	overflow := new(big.Int).Lsh(big.NewInt(1), 256)
+	_, err := (&Value{Type: Int256, Val: overflow}).Serialize()
+	assert.EqualError(err, "int256 overflow: "+overflow.String()+" does not fit into 256 bits")
+
+	_, err = (&Value{Type: Int256, Val: big.NewInt(-1)}).Serialize()
+	assert.EqualError(err, "int256 cannot be negative: -1")
+
+	_, err = Deserialize(make([]byte, 31), Int256)
+	assert.EqualError(err, "Int256: expected 32 bytes, got 31")
+}
+
+func TestRationalRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	vals, err := ToValues([]interface{}{big.NewRat(1, 3)})
+	require.NoError(err)
+	assert.Equal(Rational, vals[0].Type)
+
+	data, err := vals[0].Serialize()
+	require.NoError(err)
+	assert.Equal([]byte{0x02, 0x00, 0x01, 0x01, 0x03}, data)
+
+	v, err := Deserialize(data, Rational)
+	require.NoError(err)
+	assert.Equal(0, big.NewRat(1, 3).Cmp(v.Val.(*big.Rat)))
+	assert.Equal("*big.Rat(1/3)", v.String())
+}
+
+func TestRationalDeserializeFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	// 1/0
+	_, err := Deserialize([]byte{0x02, 0x00, 0x01, 0x00}, Rational)
+	assert.EqualError(err, "invalid rational encoding: zero denominator")
+
+	_, err = Deserialize([]byte{0x02, 0x00, 0x01}, Rational)
+	assert.EqualError(err, "invalid rational encoding: expected 2 segments, got 1")
+
+	_, err = Deserialize([]byte{0x02, 0x00, 0x01, 0x02, 0x03}, Rational)
+	assert.EqualError(err, "invalid rational encoding: segment 1 is truncated: expected 2 bytes, got 1")
+}
+
+func TestPeerIDRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	hash, err := mh.Sum([]byte("peer"), mh.SHA2_256, -1)
+	require.NoError(err)
+	id := peer.ID(hash)
+
+	vals, err := ToValues([]interface{}{id})
+	require.NoError(err)
+	assert.Equal(PeerID, vals[0].Type)
+	assert.Equal("peer.ID("+peer.IDB58Encode(id)+")", vals[0].String())
+
+	data, err := vals[0].Serialize()
+	require.NoError(err)
+	assert.Equal([]byte(hash), data)
+
+	v, err := Deserialize(data, PeerID)
+	require.NoError(err)
+	assert.Equal(id, v.Val)
+}
+
+func TestPeerIDDeserializeFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Deserialize([]byte("not a multihash"), PeerID)
+	assert.Error(err)
+	assert.Contains(err.Error(), "invalid peer id encoding")
+}
+
+func TestAddressSet(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	addrGetter := address.NewForTestGetter()
+	a, b, c := addrGetter(), addrGetter(), addrGetter()
+
+	vals, err := ToValues([]interface{}{[]address.Address{c, a, b, a}})
+	require.NoError(err)
+	assert.Equal(AddressSet, vals[0].Type)
+
+	data, err := vals[0].Serialize()
+	require.NoError(err)
+	assert.Len(data, 3*address.Length)
+
+	// the encoding doesn't depend on the order or duplicates
+	for _, perm := range [][]address.Address{{a, b, c}, {b, c, a}, {c, c, b, a}} {
+		other := &Value{Type: AddressSet, Val: perm}
+		otherData, err := other.Serialize()
+		require.NoError(err)
+		assert.Equal(data, otherData)
+		assert.True(vals[0].Equals(other))
+	}
+	assert.False(vals[0].Equals(&Value{Type: AddressSet, Val: []address.Address{a, b}}))
+
+	out, err := Deserialize(data, AddressSet)
+	require.NoError(err)
+	addrs := out.Val.([]address.Address)
+	require.Len(addrs, 3)
+	for i := 1; i < len(addrs); i++ {
+		assert.True(bytes.Compare(addrs[i-1][:], addrs[i][:]) < 0)
+	}
+	assert.ElementsMatch([]address.Address{a, b, c}, addrs)
+
+	empty, err := Deserialize(nil, AddressSet)
+	require.NoError(err)
+	assert.True(empty.Equals(&Value{Type: AddressSet, Val: []address.Address(nil)}))
+}
+
+func TestAddressSetFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	addrs, err := (&Value{Type: AddressSet, Val: []address.Address{address.MakeTestAddress("a"), address.MakeTestAddress("b")}}).Serialize()
+	assert.NoError(err)
+
+	// a duplicate
+	_, err = Deserialize(append(addrs[:address.Length:address.Length], addrs[:address.Length]...), AddressSet)
+	assert.EqualError(err, "invalid address set encoding: duplicate address "+address.MakeTestAddress("b").String())
+
+	// swapped
+	swapped := append(append([]byte{}, addrs[address.Length:]...), addrs[:address.Length]...)
+	_, err = Deserialize(swapped, AddressSet)
+	assert.EqualError(err, "invalid address set encoding: addresses out of order")
+
+	_, err = Deserialize(addrs[:address.Length+1], AddressSet)
+	assert.EqualError(err, fmt.Sprintf("invalid address set encoding: length %d is not a multiple of %d", address.Length+1, address.Length))
+}
+
+func TestInt64RoundTrip(t *testing.T) {
+	for _, n := range []int64{math.MinInt64, -1, 0, math.MaxInt64} {
+		assert := assert.New(t)
+
+		vals, err := ToValues([]interface{}{n})
+		assert.NoError(err)
+		assert.Equal(Int64, vals[0].Type)
+
+		data, err := vals[0].Serialize()
+		assert.NoError(err)
+		assert.Len(data, 8)
+
+		out, err := Deserialize(data, Int64)
+		assert.NoError(err)
+		assert.Equal(n, out.Val)
+	}
+
+	data, err := (&Value{Type: Int64, Val: int64(-2)}).Serialize()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}, data)
+
+	_, err = Deserialize(make([]byte, 9), Int64)
+	assert.EqualError(t, err, "Int64: expected 8 bytes, got 9")
+}
+
+func TestFloat64RoundTrip(t *testing.T) {
+	for _, f := range []float64{0, -1.5, math.MaxFloat64, math.SmallestNonzeroFloat64, math.Inf(1), math.Inf(-1), math.NaN()} {
+		assert := assert.New(t)
+
+		in := &Value{Type: Float64, Val: f}
+		data, err := in.Serialize()
+		assert.NoError(err)
+		assert.Len(data, 8)
+
+		out, err := Deserialize(data, Float64)
+		assert.NoError(err)
+		assert.Equal(math.Float64bits(f), math.Float64bits(out.Val.(float64)))
+		assert.True(in.Equals(out), "%s != %s", in, out)
+	}
+}
+
+func TestFloat64DeserializeFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Deserialize(make([]byte, 7), Float64)
+	assert.EqualError(err, "Float64: expected 8 bytes, got 7")
+
+	_, err = Deserialize(nil, Float64)
+	assert.EqualError(err, "Float64: expected 8 bytes, got 0")
+}
+
+func TestDeserializeWithLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	v, err := DeserializeWithLimit(make([]byte, 16), Bytes, 16)
+	assert.NoError(err)
+	assert.Len(v.Val, 16)
+
+	_, err = DeserializeWithLimit(make([]byte, 17), Bytes, 16)
+	assert.EqualError(err, "[]byte value exceeds size limit: limit is 16 bytes, got 17")
+
+	v, err = DeserializeWithLimit([]byte("abcd"), String, 4)
+	assert.NoError(err)
+	assert.Equal("abcd", v.Val)
+
+	_, err = DeserializeWithLimit([]byte("abcde"), String, 4)
+	assert.EqualError(err, "string value exceeds size limit: limit is 4 bytes, got 5")
+
+	// other types are not affected by the limit
+	_, err = DeserializeWithLimit(make([]byte, 8), Uint64, 4)
+	assert.NoError(err)
+}
+
+func TestValueClone(t *testing.T) {
+	assert := assert.New(t)
+
+	orig := &Value{Type: Bytes, Val: []byte{1, 2, 3}}
+	clone := orig.Clone()
+	assert.True(orig.Equals(clone))
+
+	clone.Val.([]byte)[0] = 9
+	assert.Equal([]byte{1, 2, 3}, orig.Val)
+
+	origInt := &Value{Type: Integer, Val: big.NewInt(5)}
+	cloneInt := origInt.Clone()
+	cloneInt.Val.(*big.Int).SetInt64(6)
+	assert.Equal(big.NewInt(5), origInt.Val)
+
+	addr := address.NewForTestGetter()()
+	assert.Equal(&Value{Type: Address, Val: addr}, (&Value{Type: Address, Val: addr}).Clone())
+	assert.Equal(&Value{Type: String, Val: "foo"}, (&Value{Type: String, Val: "foo"}).Clone())
+
+	invalid := &Value{}
+	cloneInvalid := invalid.Clone()
+	assert.Equal(invalid, cloneInvalid)
+	assert.False(invalid == cloneInvalid)
+
+	assert.Nil((*Value)(nil).Clone())
+}
+
+func TestDeserializeBytesAliasing(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := []byte{1, 2, 3}
+	v, err := Deserialize(buf, Bytes)
+	assert.NoError(err)
+
+	buf[0] = 9
+	assert.Equal([]byte{1, 2, 3}, v.Val)
+
+	v, err = DeserializeNoCopy(buf, Bytes)
+	assert.NoError(err)
+
+	buf[1] = 9
+	assert.Equal([]byte{9, 9, 3}, v.Val)
+}
+
+func TestBytesNilAndEmpty(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, b := range [][]byte{nil, {}} {
+		data, err := (&Value{Type: Bytes, Val: b}).Serialize()
+		assert.NoError(err)
+		assert.NotNil(data)
+		assert.Len(data, 0)
+
+		for _, decode := range []func([]byte, Type) (*Value, error){Deserialize, DeserializeNoCopy} {
+			v, err := decode(b, Bytes)
+			assert.NoError(err)
+			assert.NotNil(v.Val)
+			assert.Equal([]byte{}, v.Val)
+		}
+	}
+}
+
+func TestAttoFILRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	amount, ok := types.NewAttoFILFromFILString("1.5")
+	assert.True(ok)
+
+	vals, err := ToValues([]interface{}{amount})
+	assert.NoError(err)
+	assert.Equal(AttoFIL, vals[0].Type)
+	assert.Equal("*types.AttoFIL(1.5)", vals[0].String())
+
+	data, err := vals[0].Serialize()
+	assert.NoError(err)
+
+	v, err := Deserialize(data, AttoFIL)
+	assert.NoError(err)
+	assert.True(amount.Equal(v.Val.(*types.AttoFIL)))
+}
+
+func TestAttoFILRejectsNegative(t *testing.T) {
+	assert := assert.New(t)
+
+	negative := types.NewZeroAttoFIL().Sub(types.NewAttoFILFromFIL(1))
+	_, err := (&Value{Type: AttoFIL, Val: negative}).Serialize()
+	assert.EqualError(err, "token amount cannot be negative: -1")
+}
+
+func TestDeserializeAddressValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := address.NewForTestGetter()()
+	v, err := Deserialize(addr.Bytes(), Address)
+	assert.NoError(err)
+	assert.Equal(addr, v.Val)
+
+	_, err = Deserialize(addr.Bytes()[:3], Address)
+	assert.EqualError(err, "Address: expected 22 bytes, got 3")
+
+	_, err = Deserialize([]byte{}, Address)
+	assert.EqualError(err, "Address: expected 22 bytes, got 0")
+
+	_, err = Deserialize(append(addr.Bytes(), 0), Address)
+	assert.EqualError(err, "Address: expected 22 bytes, got 23")
+
+	unknownNetwork := append([]byte{}, addr.Bytes()...)
+	unknownNetwork[0] = 7
+	_, err = Deserialize(unknownNetwork, Address)
+	assert.EqualError(err, "invalid address encoding: unknown network")
+}
+
+func TestTypeOf(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := cid.NewPrefixV1(cid.DagCBOR, mh.SHA2_256).Sum([]byte("piece"))
+	assert.NoError(err)
+
+	cases := []struct {
+		val interface{}
+		typ Type
+	}{
+		{address.NewForTestGetter()(), Address},
+		{types.NewAttoFILFromFIL(1), AttoFIL},
+		{types.NewBytesAmount(1), BytesAmount},
+		{types.NewChannelID(1), ChannelID},
+		{types.NewBlockHeight(1), BlockHeight},
+		{big.NewInt(1), Integer},
+		{[]byte("foo"), Bytes},
+		{"foo", String},
+		{[]uint64{1}, UintArray},
+		{peer.ID("foo"), PeerID},
+		{uint64(1), SectorID},
+		{map[string]types.Commitments{}, CommitmentsMap},
+		{true, Boolean},
+		{c, Cid},
+		{big.NewRat(1, 3), Rational},
+		{int64(-1), Int64},
+		{[]address.Address{}, AddressSet},
+	}
+
+	for _, tcase := range cases {
+		typ, ok := TypeOf(tcase.val)
+		assert.True(ok)
+		assert.Equal(tcase.typ, typ, "wrong type for %T", tcase.val)
+	}
+
+	for _, v := range []interface{}{nil, 17, 1.5, struct{}{}} {
+		typ, ok := TypeOf(v)
+		assert.False(ok)
+		assert.Equal(Invalid, typ)
+	}
+}
+
+func TestNilValue(t *testing.T) {
+	assert := assert.New(t)
+
+	var v *Value
+	assert.NotPanics(func() {
+		_, err := v.Serialize()
+		assert.Equal(ErrNilValue, err)
+	})
+
+	assert.Equal("<nil>", v.String())
+
+	_, err := v.MarshalCBOR()
+	assert.Equal(ErrNilValue, err)
+
+	_, err = v.AsInteger()
+	assert.Equal(ErrNilValue, err)
+}
+
+func TestBlockHeightRoundTrip(t *testing.T) {
+	for _, h := range []*types.BlockHeight{types.NewBlockHeight(0), types.NewBlockHeight(1 << 62), types.NewBlockHeight(math.MaxUint64)} {
+		assert := assert.New(t)
+
+		vals, err := ToValues([]interface{}{h})
+		assert.NoError(err)
+		assert.Equal(BlockHeight, vals[0].Type)
+
+		data, err := vals[0].Serialize()
+		assert.NoError(err)
+
+		v, err := Deserialize(data, BlockHeight)
+		assert.NoError(err)
+		assert.True(h.Equal(v.Val.(*types.BlockHeight)), "expected %s, got %s", h, v.Val)
+	}
+}
+
+func TestBlockHeightDeserializeFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Deserialize([]byte{}, BlockHeight)
+	assert.EqualError(err, "BlockHeight: expected 8 bytes, got 0")
+
+	_, err = Deserialize([]byte{0x01, 0x01, 0x01}, BlockHeight)
+	assert.EqualError(err, "BlockHeight: expected 8 bytes, got 3")
+
+	_, err = Deserialize(make([]byte, 9), BlockHeight)
+	assert.EqualError(err, "BlockHeight: expected 8 bytes, got 9")
+}
+
+func TestBlockHeightSerialize(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := (&Value{Type: BlockHeight, Val: types.NewBlockHeight(0x0102)}).Serialize()
+	assert.NoError(err)
+	assert.Equal([]byte{0, 0, 0, 0, 0, 0, 0x01, 0x02}, data)
+
+	_, err = (&Value{Type: BlockHeight, Val: (*types.BlockHeight)(nil)}).Serialize()
+	assert.EqualError(err, "nil *types.BlockHeight value")
+
+	_, err = (&Value{Type: BlockHeight, Val: types.NewBlockHeight(0).Sub(types.NewBlockHeight(1))}).Serialize()
+	assert.EqualError(err, "block height out of range: -1")
+
+	tooHigh, _ := types.NewBlockHeightFromString("18446744073709551616", 10)
+	_, err = (&Value{Type: BlockHeight, Val: tooHigh}).Serialize()
+	assert.EqualError(err, "block height out of range: 18446744073709551616")
+}
+
+func TestValueIsZero(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := address.NewForTestGetter()()
+
+	zero := []*Value{
+		{},
+		{Type: Bytes, Val: []byte{}},
+		{Type: Bytes, Val: []byte(nil)},
+		{Type: String, Val: ""},
+		{Type: Integer, Val: big.NewInt(0)},
+		{Type: Address, Val: address.Address{}},
+		{Type: AttoFIL, Val: types.NewZeroAttoFIL()},
+		{Type: BlockHeight, Val: types.NewBlockHeight(0)},
+		{Type: Boolean, Val: false},
+		{Type: SectorID, Val: uint64(0)},
+		{Type: Cid, Val: cid.Undef},
+	}
+	for _, v := range zero {
+		assert.True(v.IsZero(), "expected %s to be zero", v)
+	}
+
+	nonZero := []*Value{
+		{Type: Bytes, Val: []byte{0}},
+		{Type: String, Val: "a"},
+		{Type: Integer, Val: big.NewInt(-1)},
+		{Type: Address, Val: addr},
+		{Type: AttoFIL, Val: types.NewAttoFILFromFIL(1)},
+		{Type: BlockHeight, Val: types.NewBlockHeight(1)},
+		{Type: Boolean, Val: true},
+		{Type: SectorID, Val: uint64(1)},
+	}
+	for _, v := range nonZero {
+		assert.False(v.IsZero(), "expected %s to not be zero", v)
+	}
+}
+
+func TestHash(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	a, err := (&Value{Type: Bytes, Val: []byte("blob")}).Hash()
+	require.NoError(err)
+	b, err := (&Value{Type: Bytes, Val: []byte("blob")}).Hash()
+	require.NoError(err)
+	assert.True(a.Equals(b))
+
+	c, err := (&Value{Type: Bytes, Val: []byte("blub")}).Hash()
+	require.NoError(err)
+	assert.False(a.Equals(c))
+
+	_, err = (&Value{Type: Bytes, Val: "blob"}).Hash()
+	assert.Error(err)
+}
+
+func TestHashFloat64(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	zero := &Value{Type: Float64, Val: 0.0}
+	negZero := &Value{Type: Float64, Val: math.Copysign(0, -1)}
+	nan := &Value{Type: Float64, Val: math.NaN()}
+	otherNaN := &Value{Type: Float64, Val: math.Float64frombits(math.Float64bits(math.NaN()) ^ 2)}
+
+	for _, pair := range [][2]*Value{{zero, zero}, {zero, negZero}, {nan, nan}, {nan, otherNaN}} {
+		a, err := pair[0].Hash()
+		require.NoError(err)
+		b, err := pair[1].Hash()
+		require.NoError(err)
+		assert.Equal(pair[0].Equals(pair[1]), a.Equals(b), "%s vs %s", pair[0], pair[1])
+	}
+
+	assert.False(zero.Equals(negZero))
+	assert.True(nan.Equals(nan))
+	assert.False(nan.Equals(otherNaN))
+}
+
+func TestSerializeAppend(t *testing.T) {
+	prefix := []byte("prefix")
+
+	for name, v := range canonicalValues(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			expected, err := v.Serialize()
+			require.NoError(err)
+
+			dst := append(make([]byte, 0, 64), prefix...)
+			out, err := v.SerializeAppend(dst)
+			require.NoError(err)
+			assert.Equal(prefix, out[:len(prefix)])
+			assert.Equal(expected, out[len(prefix):])
+		})
+	}
+
+	// errors leave what was appended before in place
+	out, err := (*Value)(nil).SerializeAppend(prefix)
+	assert.Equal(t, ErrNilValue, err)
+	assert.Equal(t, prefix, out)
+
+	out, err = (&Value{Type: Integer, Val: "foo"}).SerializeAppend(prefix)
+	assert.EqualError(t, err, "expected type *big.Int, got string")
+	assert.Equal(t, prefix, out)
+}
+
+func BenchmarkSerialize(b *testing.B) {
+	vals := []*Value{
+		{Type: Integer, Val: big.NewInt(123456789)},
+		{Type: String, Val: "flugzeug"},
+		{Type: Uint64, Val: uint64(42)},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, v := range vals {
+			if _, err := v.Serialize(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestIntegerFastPath(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, n := range []int64{0, 1, 255, 256, -1, -255, -256, 1 << 40} {
+		intgr := big.NewInt(n)
+
+		fast := serializeInteger(intgr)
+		assert.Equal(serializeIntegerGeneral(intgr), fast, "serializing %d", n)
+
+		out, err := deserializeInteger(fast)
+		assert.NoError(err)
+		general, err := deserializeIntegerGeneral(fast)
+		assert.NoError(err)
+		assert.Equal(0, general.Cmp(out), "deserializing %d", n)
+		assert.Equal(0, intgr.Cmp(out), "deserializing %d", n)
+	}
+
+	// non canonical two byte encodings decode the same on both paths
+	for _, data := range [][]byte{{0x00, 0x00}, {0x01, 0x00}, {0x01, 0xff}} {
+		out, err := deserializeInteger(data)
+		assert.NoError(err)
+		general, err := deserializeIntegerGeneral(data)
+		assert.NoError(err)
+		assert.Equal(0, general.Cmp(out), "deserializing %x", data)
+	}
+
+	_, err := deserializeInteger([]byte{0x02, 0x01})
+	assert.EqualError(err, "invalid integer encoding: unknown sign byte 0x2")
+}
+
+func benchmarkIntegerRoundTrip(b *testing.B, intgr *big.Int) {
+	v := &Value{Type: Integer, Val: intgr}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data, err := v.Serialize()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Deserialize(data, Integer); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIntegerSmall(b *testing.B) {
+	benchmarkIntegerRoundTrip(b, big.NewInt(42))
+}
+
+func BenchmarkIntegerLarge(b *testing.B) {
+	large, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	benchmarkIntegerRoundTrip(b, large)
+}
+
+func BenchmarkSerializeAppend(b *testing.B) {
+	vals := []*Value{
+		{Type: Integer, Val: big.NewInt(123456789)},
+		{Type: String, Val: "flugzeug"},
+		{Type: Uint64, Val: uint64(42)},
+	}
+
+	b.ReportAllocs()
+	buf := make([]byte, 0, 64)
+	for i := 0; i < b.N; i++ {
+		buf = buf[:0]
+		for _, v := range vals {
+			var err error
+			if buf, err = v.SerializeAppend(buf); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestSupportedTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]Type{
+		Address, AttoFIL, BytesAmount, ChannelID, BlockHeight, Integer, Bytes, String, UintArray,
+		PeerID, SectorID, CommitmentsMap, Boolean, Uint64, Cid, Int256, Rational, Float64, Int64,
+		AddressSet,
+	}, SupportedTypes())
+	assert.NotContains(SupportedTypes(), Invalid)
+}
+
+func TestValueMatches(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(ValueMatches(Integer, big.NewInt(1)))
+	assert.True(ValueMatches(Int256, big.NewInt(1)))
+	assert.True(ValueMatches(String, "foo"))
+	assert.True(ValueMatches(SectorID, uint64(1)))
+	assert.False(ValueMatches(Integer, 1))
+	assert.False(ValueMatches(Bytes, "foo"))
+	assert.False(ValueMatches(Invalid, "foo"))
+
+	// an untyped nil has no go type to match
+	assert.False(ValueMatches(Integer, nil))
+	assert.False(ValueMatches(Bytes, nil))
+
+	// a typed nil still carries its go type
+	assert.True(ValueMatches(Integer, (*big.Int)(nil)))
+	assert.True(ValueMatches(Bytes, []byte(nil)))
+}
+
+type namedInt big.Int
+
+type intAlias = *big.Int
+
+func TestTypeIsFixedWidth(t *testing.T) {
+	assert := assert.New(t)
+
+	for name, v := range canonicalValues(t) {
+		data, err := v.Serialize()
+		assert.NoError(err)
+
+		if width, ok := v.Type.IsFixedWidth(); ok {
+			assert.Equal(width, len(data), name)
+		}
+	}
+
+	for _, typ := range []Type{Bytes, String, Integer, SectorID, Cid, Invalid, Type(1000)} {
+		width, ok := typ.IsFixedWidth()
+		assert.False(ok, typ.String())
+		assert.Equal(0, width)
+	}
+
+	width, ok := Uint64.IsFixedWidth()
+	assert.True(ok)
+	assert.Equal(8, width)
+}
+
+func TestTypeAssignable(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(TypeAssignable(String, reflect.TypeOf("foo")))
+	assert.True(TypeAssignable(String, reflect.TypeOf(namedString("foo"))))
+	assert.True(TypeAssignable(Integer, reflect.TypeOf(intAlias(nil))))
+	assert.True(TypeAssignable(Integer, reflect.TypeOf((*namedInt)(nil))))
+
+	// TypeMatches stays strict
+	assert.False(TypeMatches(String, reflect.TypeOf(namedString("foo"))))
+	assert.False(TypeMatches(Integer, reflect.TypeOf((*namedInt)(nil))))
+
+	// convertible, but of a different kind
+	assert.False(TypeAssignable(String, reflect.TypeOf([]byte("foo"))))
+	assert.False(TypeAssignable(Int64, reflect.TypeOf(uint64(1))))
+	assert.False(TypeAssignable(Integer, reflect.TypeOf(big.Int{})))
+	assert.False(TypeAssignable(Invalid, reflect.TypeOf("foo")))
+	assert.False(TypeAssignable(String, nil))
+}
+
+func TestValueValidate(t *testing.T) {
+	for name, v := range canonicalValues(t) {
+		assert.NoError(t, v.Validate(), name)
+	}
+
+	assert := assert.New(t)
+
+	assert.EqualError((&Value{Type: BlockHeight, Val: (*types.BlockHeight)(nil)}).Validate(), "nil *types.BlockHeight value")
+
+	assert.Equal(ErrNilValue, (*Value)(nil).Validate())
+	assert.Equal(ErrInvalidType, (&Value{Type: Invalid, Val: "foo"}).Validate())
+	assert.EqualError((&Value{Type: Type(9999), Val: "foo"}).Validate(), "unrecognized Type: 9999")
+	assert.EqualError((&Value{Type: Integer, Val: "foo"}).Validate(), "expected type *big.Int, got string")
+	assert.EqualError((&Value{Type: Bytes}).Validate(), "expected type []uint8, got <nil>")
+	assert.EqualError((&Value{Type: Integer, Val: (*big.Int)(nil)}).Validate(), "nil *big.Int value")
+	assert.EqualError((&Value{Type: AttoFIL, Val: (*types.AttoFIL)(nil)}).Validate(), "nil *types.AttoFIL value")
+	assert.EqualError((&Value{Type: Bytes, Val: make([]byte, maxValueLength+1)}).Validate(),
+		"[]byte value too long: 16777217 bytes exceeds limit of 16777216")
+
+	overflow := new(big.Int).Lsh(big.NewInt(1), 256)
+	assert.EqualError((&Value{Type: Int256, Val: overflow}).Validate(), "int256 overflow: "+overflow.String()+" does not fit into 256 bits")
+	assert.EqualError((&Value{Type: Int256, Val: big.NewInt(-1)}).Validate(), "int256 cannot be negative: -1")
+	assert.NoError((&Value{Type: Int256, Val: new(big.Int).Sub(overflow, big.NewInt(1))}).Validate())
+	assert.NoError((&Value{Type: Integer, Val: big.NewInt(-1)}).Validate())
+
+	negative := types.NewAttoFIL(big.NewInt(-1))
+	assert.EqualError((&Value{Type: AttoFIL, Val: negative}).Validate(), "token amount cannot be negative: "+negative.String())
+}
+
+func TestSerializedSize(t *testing.T) {
+	vals := canonicalValues(t)
+	vals["sectorid-zero"] = &Value{Type: SectorID, Val: uint64(0)}
+	vals["sectorid-max"] = &Value{Type: SectorID, Val: uint64(1<<64 - 1)}
+	vals["sectorid-127"] = &Value{Type: SectorID, Val: uint64(127)}
+	vals["sectorid-128"] = &Value{Type: SectorID, Val: uint64(128)}
+	vals["integer-255"] = &Value{Type: Integer, Val: big.NewInt(255)}
+	vals["integer-256"] = &Value{Type: Integer, Val: big.NewInt(-256)}
+
+	for name, v := range vals {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			data, err := v.Serialize()
+			assert.NoError(err)
+
+			size, err := v.SerializedSize()
+			assert.NoError(err)
+			assert.Equal(len(data), size)
+		})
+	}
+
+	_, err := (*Value)(nil).SerializedSize()
+	assert.Equal(t, ErrNilValue, err)
+
+	_, err = (&Value{Type: Integer, Val: "foo"}).SerializedSize()
+	assert.EqualError(t, err, "expected type *big.Int, got string")
+}
+
+func TestMustDeserialize(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(&Value{Type: Boolean, Val: true}, MustDeserialize([]byte{0x01}, Boolean))
+	assert.Panics(func() { MustDeserialize([]byte{0x02}, Boolean) })
+	assert.Panics(func() { MustDeserialize(nil, Invalid) })
+}
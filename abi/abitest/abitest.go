@@ -0,0 +1,43 @@
+// Package abitest provides helpers for testing abi encodings.
+package abitest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/abi"
+)
+
+// RoundTripCheck fails the test unless every value serializes to the same
+// bytes on repeated encodes and deserializes, using its own type, to a value
+// equal to the original.
+func RoundTripCheck(t testing.TB, vals []*abi.Value) {
+	t.Helper()
+
+	for i, v := range vals {
+		data, err := v.Serialize()
+		if err != nil {
+			t.Errorf("value %d: failed to serialize %s: %s", i, v, err)
+			continue
+		}
+
+		again, err := v.Serialize()
+		if err != nil {
+			t.Errorf("value %d: failed to serialize %s again: %s", i, v, err)
+			continue
+		}
+		if !bytes.Equal(data, again) {
+			t.Errorf("value %d: encoding of %s is not deterministic: %x != %x", i, v, data, again)
+			continue
+		}
+
+		out, err := abi.Deserialize(data, v.Type)
+		if err != nil {
+			t.Errorf("value %d: failed to deserialize %x as %s: %s", i, data, v.Type, err)
+			continue
+		}
+		if !v.Equals(out) {
+			t.Errorf("value %d: expected %s after round trip, got %s", i, v, out)
+		}
+	}
+}
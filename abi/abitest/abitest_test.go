@@ -0,0 +1,90 @@
+package abitest
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/abi"
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder captures failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// counterCodec encodes every value differently, so its encoding is never
+// deterministic.
+type counterCodec struct {
+	n *int
+}
+
+func (c counterCodec) Encode(v interface{}) ([]byte, error) {
+	*c.n++
+	return []byte{byte(*c.n)}, nil
+}
+
+func (c counterCodec) Decode(data []byte) (interface{}, error) {
+	return int(data[0]), nil
+}
+
+// lossyCodec drops all but the first byte of a string.
+type lossyCodec struct{}
+
+func (lossyCodec) Encode(v interface{}) ([]byte, error) {
+	return []byte(v.(string)), nil
+}
+
+func (lossyCodec) Decode(data []byte) (interface{}, error) {
+	return string(data[:1]), nil
+}
+
+func TestRoundTripCheckPasses(t *testing.T) {
+	vals, err := abi.ToValues([]interface{}{
+		address.NewForTestGetter()(),
+		big.NewInt(-42),
+		[]byte("beep"),
+		"flugzeug",
+		uint64(7),
+		true,
+	})
+	require.NoError(t, err)
+
+	r := &recorder{TB: t}
+	RoundTripCheck(r, vals)
+	assert.Empty(t, r.errors)
+}
+
+func TestRoundTripCheckFailures(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	counter, lossy := abi.Type(2000), abi.Type(2001)
+	require.NoError(abi.RegisterType(counter, counterCodec{n: new(int)}))
+	require.NoError(abi.RegisterType(lossy, lossyCodec{}))
+
+	r := &recorder{TB: t}
+	RoundTripCheck(r, []*abi.Value{
+		{Type: counter, Val: 0},
+		{Type: abi.String, Val: "fine"},
+		{Type: lossy, Val: "flugzeug"},
+		{Type: abi.Integer, Val: "not an integer"},
+	})
+
+	require.Len(r.errors, 3)
+	assert.Contains(r.errors[0], "value 0: encoding of")
+	assert.Contains(r.errors[0], "is not deterministic")
+	assert.Contains(r.errors[1], "value 2: expected")
+	assert.Contains(r.errors[2], "value 3: failed to serialize")
+}
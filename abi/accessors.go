@@ -0,0 +1,124 @@
+package abi
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/filecoin-project/go-filecoin/address"
+)
+
+func (av *Value) checkType(t Type) error {
+	if av == nil {
+		return ErrNilValue
+	}
+	if av.Type != t {
+		return fmt.Errorf("expected value of type %s, got %s", t, av.Type)
+	}
+	return nil
+}
+
+// AsInteger returns the *big.Int held by an Integer value.
+func (av *Value) AsInteger() (*big.Int, error) {
+	if err := av.checkType(Integer); err != nil {
+		return nil, err
+	}
+
+	intgr, ok := av.Val.(*big.Int)
+	if !ok {
+		return nil, &typeError{&big.Int{}, av.Val}
+	}
+	return intgr, nil
+}
+
+// AsAddress returns the address held by an Address value.
+func (av *Value) AsAddress() (address.Address, error) {
+	if err := av.checkType(Address); err != nil {
+		return address.Address{}, err
+	}
+
+	addr, ok := av.Val.(address.Address)
+	if !ok {
+		return address.Address{}, &typeError{address.Address{}, av.Val}
+	}
+	return addr, nil
+}
+
+// AsBytes returns the byte slice held by a Bytes value.
+func (av *Value) AsBytes() ([]byte, error) {
+	if err := av.checkType(Bytes); err != nil {
+		return nil, err
+	}
+
+	b, ok := av.Val.([]byte)
+	if !ok {
+		return nil, &typeError{[]byte{}, av.Val}
+	}
+	return b, nil
+}
+
+// AsString returns the string held by a String value.
+func (av *Value) AsString() (string, error) {
+	if err := av.checkType(String); err != nil {
+		return "", err
+	}
+
+	s, ok := av.Val.(string)
+	if !ok {
+		return "", &typeError{"", av.Val}
+	}
+	return s, nil
+}
+
+// ToBytesValue returns a new Bytes value holding the serialized bytes of av.
+func (av *Value) ToBytesValue() (*Value, error) {
+	data, err := av.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return NewBytes(data), nil
+}
+
+// BytesValueAs reinterprets the bytes held by a Bytes value as a value of
+// type t. It is the inverse of ToBytesValue.
+func (av *Value) BytesValueAs(t Type) (*Value, error) {
+	b, err := av.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+	return Deserialize(b, t)
+}
+
+// DecodeInto deserializes data into dst, which must be one of *address.Address,
+// **big.Int, *[]byte or *string. The type of the value is inferred from dst.
+func DecodeInto(data []byte, dst interface{}) error {
+	var t Type
+	switch dst.(type) {
+	case *address.Address:
+		t = Address
+	case **big.Int:
+		t = Integer
+	case *[]byte:
+		t = Bytes
+	case *string:
+		t = String
+	default:
+		return fmt.Errorf("cannot decode into %T", dst)
+	}
+
+	av, err := Deserialize(data, t)
+	if err != nil {
+		return err
+	}
+
+	switch d := dst.(type) {
+	case *address.Address:
+		*d = av.Val.(address.Address)
+	case **big.Int:
+		*d = av.Val.(*big.Int)
+	case *[]byte:
+		*d = av.Val.([]byte)
+	case *string:
+		*d = av.Val.(string)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessors(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := address.NewForTestGetter()()
+
+	intgr, err := (&Value{Type: Integer, Val: big.NewInt(5)}).AsInteger()
+	assert.NoError(err)
+	assert.Equal(big.NewInt(5), intgr)
+
+	a, err := (&Value{Type: Address, Val: addr}).AsAddress()
+	assert.NoError(err)
+	assert.Equal(addr, a)
+
+	b, err := (&Value{Type: Bytes, Val: []byte("foo")}).AsBytes()
+	assert.NoError(err)
+	assert.Equal([]byte("foo"), b)
+
+	s, err := (&Value{Type: String, Val: "foo"}).AsString()
+	assert.NoError(err)
+	assert.Equal("foo", s)
+}
+
+func TestAccessorsTypeMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	str := &Value{Type: String, Val: "foo"}
+
+	_, err := str.AsInteger()
+	assert.EqualError(err, "expected value of type *big.Int, got string")
+	_, err = str.AsAddress()
+	assert.EqualError(err, "expected value of type address.Address, got string")
+	_, err = str.AsBytes()
+	assert.EqualError(err, "expected value of type []byte, got string")
+	_, err = (&Value{Type: Bytes, Val: []byte("foo")}).AsString()
+	assert.EqualError(err, "expected value of type string, got []byte")
+
+	// the type tag matches, but the go value doesn't
+	_, err = (&Value{Type: Integer, Val: "foo"}).AsInteger()
+	assert.EqualError(err, "expected type *big.Int, got string")
+	_, err = (&Value{Type: Address, Val: "foo"}).AsAddress()
+	assert.EqualError(err, "expected type address.Address, got string")
+	_, err = (&Value{Type: Bytes, Val: "foo"}).AsBytes()
+	assert.EqualError(err, "expected type []uint8, got string")
+	_, err = (&Value{Type: String, Val: 7}).AsString()
+	assert.EqualError(err, "expected type string, got int")
+}
+
+func TestBytesValueRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	addr := NewAddress(address.NewForTestGetter()())
+	b, err := addr.ToBytesValue()
+	require.NoError(err)
+	assert.Equal(Bytes, b.Type)
+	assert.Equal(addr.Val.(address.Address).Bytes(), b.Val)
+
+	out, err := b.BytesValueAs(Address)
+	require.NoError(err)
+	assert.True(addr.Equals(out), "expected %s, got %s", addr, out)
+
+	_, err = addr.BytesValueAs(Address)
+	assert.EqualError(err, "expected value of type []byte, got address.Address")
+
+	_, err = (&Value{Type: String, Val: 1}).ToBytesValue()
+	assert.Error(err)
+}
+
+func TestDecodeInto(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	encode := func(v interface{}) []byte {
+		vals, err := ToValues([]interface{}{v})
+		require.NoError(err)
+		data, err := vals[0].Serialize()
+		require.NoError(err)
+		return data
+	}
+
+	addr := address.NewForTestGetter()()
+	var outAddr address.Address
+	require.NoError(DecodeInto(encode(addr), &outAddr))
+	assert.Equal(addr, outAddr)
+
+	var outInt *big.Int
+	require.NoError(DecodeInto(encode(big.NewInt(-42)), &outInt))
+	assert.Equal(big.NewInt(-42), outInt)
+
+	var outBytes []byte
+	require.NoError(DecodeInto(encode([]byte("beep")), &outBytes))
+	assert.Equal([]byte("beep"), outBytes)
+
+	var outStr string
+	require.NoError(DecodeInto(encode("flugzeug"), &outStr))
+	assert.Equal("flugzeug", outStr)
+}
+
+func TestDecodeIntoUnsupported(t *testing.T) {
+	assert := assert.New(t)
+
+	var n uint64
+	assert.EqualError(DecodeInto([]byte{1}, &n), "cannot decode into *uint64")
+	assert.EqualError(DecodeInto([]byte{1}, "foo"), "cannot decode into string")
+	assert.EqualError(DecodeInto([]byte{1}, nil), "cannot decode into <nil>")
+}
@@ -0,0 +1,53 @@
+package abi
+
+import (
+	"fmt"
+	"math/big"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+)
+
+// Add returns a new Integer value holding the sum of av and other, which must
+// both be Integer values.
+func (av *Value) Add(other *Value) (*Value, error) {
+	return av.AddWithSign(other, Signed)
+}
+
+// Sub returns a new Integer value holding av minus other, which must both be
+// Integer values.
+func (av *Value) Sub(other *Value) (*Value, error) {
+	return av.SubWithSign(other, Signed)
+}
+
+// AddWithSign works like Add, but for Unsigned it errors if the result is
+// negative.
+func (av *Value) AddWithSign(other *Value, sign Signedness) (*Value, error) {
+	return av.arith(other, sign, "add", (*big.Int).Add)
+}
+
+// SubWithSign works like Sub, but for Unsigned it errors if the result is
+// negative, as is needed for token amounts.
+func (av *Value) SubWithSign(other *Value, sign Signedness) (*Value, error) {
+	return av.arith(other, sign, "subtract", (*big.Int).Sub)
+}
+
+func (av *Value) arith(other *Value, sign Signedness, name string, op func(z, x, y *big.Int) *big.Int) (*Value, error) {
+	x, err := av.AsInteger()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot %s", name)
+	}
+	y, err := other.AsInteger()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot %s", name)
+	}
+	if x == nil || y == nil {
+		return nil, fmt.Errorf("cannot %s: nil %s value", name, Integer)
+	}
+
+	z := op(new(big.Int), x, y)
+	if sign == Unsigned && z.Sign() < 0 {
+		return nil, fmt.Errorf("cannot %s: result %s is negative", name, z)
+	}
+
+	return NewInteger(z), nil
+}
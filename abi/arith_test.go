@@ -0,0 +1,53 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegerAdd(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	a, b := NewInteger(big.NewInt(40)), NewInteger(big.NewInt(2))
+	sum, err := a.Add(b)
+	require.NoError(err)
+	assert.Equal(big.NewInt(42), sum.Val)
+
+	// the operands are left alone
+	assert.Equal(big.NewInt(40), a.Val)
+	assert.Equal(big.NewInt(2), b.Val)
+}
+
+func TestIntegerSub(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	a, b := NewInteger(big.NewInt(2)), NewInteger(big.NewInt(40))
+	diff, err := a.Sub(b)
+	require.NoError(err)
+	assert.Equal(big.NewInt(-38), diff.Val)
+
+	_, err = a.SubWithSign(b, Unsigned)
+	assert.EqualError(err, "cannot subtract: result -38 is negative")
+
+	diff, err = b.SubWithSign(a, Unsigned)
+	require.NoError(err)
+	assert.Equal(big.NewInt(38), diff.Val)
+}
+
+func TestIntegerArithTypeMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewInteger(big.NewInt(1)).Add(NewString("1"))
+	assert.EqualError(err, "cannot add: expected value of type *big.Int, got string")
+
+	_, err = NewString("1").Sub(NewInteger(big.NewInt(1)))
+	assert.EqualError(err, "cannot subtract: expected value of type *big.Int, got string")
+
+	_, err = NewInteger(nil).Add(NewInteger(big.NewInt(1)))
+	assert.EqualError(err, "cannot add: nil *big.Int value")
+}
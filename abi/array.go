@@ -0,0 +1,90 @@
+package abi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+)
+
+// SerializeArray encodes a homogeneous list of go values of the given ABI
+// type. The element type is stored once, followed by the number of elements and
+// each length-prefixed element. A nil list is encoded as nil, while an empty
+// list has a proper encoding, so the two survive a round trip.
+func SerializeArray(t Type, vals []interface{}) ([]byte, error) {
+	if t == Invalid {
+		return nil, ErrInvalidType
+	}
+	if vals == nil {
+		return nil, nil
+	}
+
+	var buf [binary.MaxVarintLen64]byte
+	out := append([]byte{}, buf[:binary.PutUvarint(buf[:], uint64(t))]...)
+	out = append(out, buf[:binary.PutUvarint(buf[:], uint64(len(vals)))]...)
+
+	for i, v := range vals {
+		av := &Value{Type: t, Val: v}
+		if err := av.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "array element %d", i)
+		}
+
+		data, err := av.Serialize()
+		if err != nil {
+			return nil, errors.Wrapf(err, "array element %d", i)
+		}
+
+		out = appendSegment(out, data)
+	}
+
+	return out, nil
+}
+
+// DeserializeArray decodes a list encoded by SerializeArray. It errors if the
+// stored element type doesn't match t or any element fails to decode as t.
+func DeserializeArray(data []byte, t Type) ([]interface{}, error) {
+	if t == Invalid {
+		return nil, ErrInvalidType
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	et, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid array element type")
+	}
+	if Type(et) != t {
+		return nil, fmt.Errorf("expected array of %s, got array of %s", t, Type(et))
+	}
+	data = data[n:]
+
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid array length")
+	}
+
+	segments, err := readSegments(data[n:])
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(segments)) != count {
+		return nil, fmt.Errorf("expected %d array elements, but got %d", count, len(segments))
+	}
+
+	out := make([]interface{}, 0, len(segments))
+	for i, seg := range segments {
+		v, err := Deserialize(seg, t)
+		if err != nil {
+			return nil, errors.Wrapf(err, "array element %d", i)
+		}
+		if _, builtin := typeTable[t]; builtin && !TypeMatches(t, reflect.TypeOf(v.Val)) {
+			return nil, fmt.Errorf("array element %d: expected %s, got %T", i, t, v.Val)
+		}
+
+		out = append(out, v.Val)
+	}
+
+	return out, nil
+}
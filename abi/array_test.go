@@ -0,0 +1,108 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrayRoundTrip(t *testing.T) {
+	addrGetter := address.NewForTestGetter()
+
+	cases := []struct {
+		name string
+		typ  Type
+		vals []interface{}
+	}{
+		{"addresses", Address, []interface{}{addrGetter(), addrGetter(), addrGetter()}},
+		{"integers", Integer, []interface{}{big.NewInt(1), big.NewInt(-20), big.NewInt(300)}},
+		{"empty", Integer, []interface{}{}},
+		{"nil", Address, nil},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			data, err := SerializeArray(tcase.typ, tcase.vals)
+			require.NoError(err)
+
+			out, err := DeserializeArray(data, tcase.typ)
+			require.NoError(err)
+			assert.Equal(tcase.vals, out)
+		})
+	}
+}
+
+func TestArrayEmptyIsNotNil(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := SerializeArray(Bytes, []interface{}{})
+	assert.NoError(err)
+	assert.NotEmpty(data)
+
+	out, err := DeserializeArray(data, Bytes)
+	assert.NoError(err)
+	assert.NotNil(out)
+	assert.Len(out, 0)
+}
+
+func TestArrayFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := SerializeArray(Integer, []interface{}{big.NewInt(1), "foo"})
+	assert.EqualError(err, "array element 1: expected type *big.Int, got string")
+
+	_, err = SerializeArray(Integer, []interface{}{big.NewInt(1), (*big.Int)(nil)})
+	assert.EqualError(err, "array element 1: nil *big.Int value")
+
+	data, err := SerializeArray(String, []interface{}{"foo", "bar"})
+	assert.NoError(err)
+
+	_, err = DeserializeArray(data, Bytes)
+	assert.EqualError(err, "expected array of []byte, got array of string")
+
+	_, err = DeserializeArray(data[:len(data)-1], String)
+	assert.EqualError(err, "segment 1 is truncated: expected 3 bytes, got 2")
+
+	_, err = DeserializeArray(data[:len(data)-4], String)
+	assert.EqualError(err, "expected 2 array elements, but got 1")
+
+	// element errors keep their cause
+	data, err = SerializeArray(Boolean, []interface{}{true})
+	assert.NoError(err)
+	data[len(data)-1] = 2
+	_, err = DeserializeArray(data, Boolean)
+	assert.EqualError(err, "array element 0: invalid boolean encoding: 0x2")
+	assert.EqualError(errors.Cause(err), "invalid boolean encoding: 0x2")
+
+	_, err = SerializeArray(Invalid, nil)
+	assert.Equal(ErrInvalidType, err)
+}
+
+func TestArrayCustomType(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	bitfield := Type(1000)
+	require.NoError(RegisterType(bitfield, bitfieldCodec{}))
+	defer unregisterType(bitfield)
+
+	in := []interface{}{[]bool{true, false}, []bool{false}}
+	data, err := SerializeArray(bitfield, in)
+	require.NoError(err)
+
+	out, err := DeserializeArray(data, bitfield)
+	require.NoError(err)
+	assert.Equal(in, out)
+
+	_, err = SerializeArray(bitfield, []interface{}{[]bool{true}, "foo"})
+	assert.EqualError(err, "array element 1: expected type []bool, got string")
+}
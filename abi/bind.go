@@ -0,0 +1,117 @@
+package abi
+
+import (
+	"fmt"
+	"reflect"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+)
+
+// BindArgs checks the values against the parameters of the function fn and
+// returns them as reflect.Values, ready to be passed to reflect.Value.Call.
+func BindArgs(fn interface{}, vals []*Value) ([]reflect.Value, error) {
+	ft := reflect.TypeOf(fn)
+	if ft == nil || ft.Kind() != reflect.Func {
+		return nil, fmt.Errorf("expected a function, got %T", fn)
+	}
+	if ft.IsVariadic() {
+		return nil, fmt.Errorf("cannot bind arguments of variadic function %s", ft)
+	}
+	if ft.NumIn() != len(vals) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", ft.NumIn(), len(vals))
+	}
+
+	args := make([]reflect.Value, len(vals))
+	for i, v := range vals {
+		if v == nil {
+			return nil, errors.Wrapf(ErrNilValue, "argument %d", i)
+		}
+
+		pt := ft.In(i)
+		if !TypeMatches(v.Type, pt) {
+			return nil, fmt.Errorf("argument %d: cannot use value of type %s as %s", i, v.Type, pt)
+		}
+		if !ValueMatches(v.Type, v.Val) {
+			return nil, errors.Wrapf(&typeError{reflect.Zero(pt).Interface(), v.Val}, "argument %d", i)
+		}
+
+		args[i] = reflect.ValueOf(v.Val)
+	}
+	return args, nil
+}
+
+// typesByConstName maps the names of the Type constants to their values, for
+// use in struct tags.
+var typesByConstName = map[string]Type{
+	"Address":        Address,
+	"AttoFIL":        AttoFIL,
+	"BytesAmount":    BytesAmount,
+	"ChannelID":      ChannelID,
+	"BlockHeight":    BlockHeight,
+	"Integer":        Integer,
+	"Bytes":          Bytes,
+	"String":         String,
+	"UintArray":      UintArray,
+	"PeerID":         PeerID,
+	"SectorID":       SectorID,
+	"CommitmentsMap": CommitmentsMap,
+	"Boolean":        Boolean,
+	"Uint64":         Uint64,
+	"Cid":            Cid,
+	"Int256":         Int256,
+	"Rational":       Rational,
+	"Float64":        Float64,
+	"Int64":          Int64,
+	"AddressSet":     AddressSet,
+}
+
+// DecodeStruct assigns the values, in order, to the fields of the struct dst
+// points to that carry an abi tag. The tag holds the name of the Type constant
+// the field expects, like `abi:"Address"`. Fields may be of a named type that
+// the go type of the value converts to, see TypeAssignable.
+func DecodeStruct(vals []*Value, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a pointer to a struct, got %T", dst)
+	}
+	sv := rv.Elem()
+	st := sv.Type()
+
+	var fields []int
+	for i := 0; i < st.NumField(); i++ {
+		if _, ok := st.Field(i).Tag.Lookup("abi"); ok {
+			fields = append(fields, i)
+		}
+	}
+	if len(fields) != len(vals) {
+		return fmt.Errorf("expected %d parameters, but got %d", len(fields), len(vals))
+	}
+
+	for i, fi := range fields {
+		f := st.Field(fi)
+		t, ok := typesByConstName[f.Tag.Get("abi")]
+		if !ok {
+			return fmt.Errorf("field %s: unknown abi type %q", f.Name, f.Tag.Get("abi"))
+		}
+		if f.PkgPath != "" {
+			return fmt.Errorf("field %s: cannot assign to unexported field", f.Name)
+		}
+		if !TypeAssignable(t, f.Type) {
+			return fmt.Errorf("field %s: cannot hold a value of type %s", f.Name, t)
+		}
+
+		v := vals[i]
+		if v == nil {
+			return errors.Wrapf(ErrNilValue, "parameter %d", i)
+		}
+		if v.Type != t {
+			return fmt.Errorf("parameter %d: expected %s, got %s", i, t, v.Type)
+		}
+		if !ValueMatches(t, v.Val) {
+			return errors.Wrapf(&typeError{reflect.Zero(typeTable[t]).Interface(), v.Val}, "parameter %d", i)
+		}
+
+		sv.Field(fi).Set(reflect.ValueOf(v.Val).Convert(f.Type))
+	}
+	return nil
+}
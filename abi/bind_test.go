@@ -0,0 +1,116 @@
+package abi
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindArgs(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	addr := address.NewForTestGetter()()
+
+	var gotAddr address.Address
+	var gotAmount *big.Int
+	fn := func(a address.Address, amount *big.Int) {
+		gotAddr = a
+		gotAmount = amount
+	}
+
+	args, err := BindArgs(fn, []*Value{{Type: Address, Val: addr}, {Type: Integer, Val: big.NewInt(42)}})
+	require.NoError(err)
+
+	reflect.ValueOf(fn).Call(args)
+	assert.Equal(addr, gotAddr)
+	assert.Equal(big.NewInt(42), gotAmount)
+}
+
+func TestBindArgsFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	fn := func(a address.Address, amount *big.Int) {}
+
+	_, err := BindArgs(fn, []*Value{{Type: Address, Val: address.Address{}}})
+	assert.EqualError(err, "expected 2 arguments, got 1")
+
+	_, err = BindArgs(fn, []*Value{{Type: Address, Val: address.Address{}}, {Type: String, Val: "42"}})
+	assert.EqualError(err, "argument 1: cannot use value of type string as *big.Int")
+
+	_, err = BindArgs(fn, []*Value{{Type: Address, Val: address.Address{}}, {Type: Integer, Val: "42"}})
+	assert.EqualError(err, "argument 1: expected type *big.Int, got string")
+
+	_, err = BindArgs(fn, []*Value{{Type: Address, Val: address.Address{}}, nil})
+	assert.EqualError(err, "argument 1: nil value")
+
+	_, err = BindArgs("foo", nil)
+	assert.EqualError(err, "expected a function, got string")
+
+	_, err = BindArgs(func(...string) {}, nil)
+	assert.EqualError(err, "cannot bind arguments of variadic function func(...string)")
+}
+
+type transferParams struct {
+	To     address.Address `abi:"Address"`
+	Amount *big.Int        `abi:"Integer"`
+	Memo   namedString     `abi:"String"`
+
+	// untagged fields are left alone
+	Nonce uint64
+}
+
+func TestDecodeStruct(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	addr := address.NewForTestGetter()()
+	vals := []*Value{NewAddress(addr), NewInteger(big.NewInt(42)), NewString("rent")}
+
+	params := transferParams{Nonce: 7}
+	require.NoError(DecodeStruct(vals, &params))
+	assert.Equal(addr, params.To)
+	assert.Equal(big.NewInt(42), params.Amount)
+	assert.Equal(namedString("rent"), params.Memo)
+	assert.Equal(uint64(7), params.Nonce)
+}
+
+func TestDecodeStructFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := address.NewForTestGetter()()
+	vals := []*Value{NewAddress(addr), NewInteger(big.NewInt(42)), NewString("rent")}
+
+	var params transferParams
+	assert.EqualError(DecodeStruct(vals[:2], &params), "expected 3 parameters, but got 2")
+	assert.EqualError(DecodeStruct(vals, params), "expected a pointer to a struct, got abi.transferParams")
+
+	swapped := []*Value{vals[0], vals[2], vals[1]}
+	assert.EqualError(DecodeStruct(swapped, &params), "parameter 1: expected *big.Int, got string")
+
+	var badTag struct {
+		X string `abi:"Text"`
+	}
+	assert.EqualError(DecodeStruct(vals[2:], &badTag), `field X: unknown abi type "Text"`)
+
+	var badField struct {
+		X int `abi:"String"`
+	}
+	assert.EqualError(DecodeStruct(vals[2:], &badField), "field X: cannot hold a value of type string")
+}
+
+func TestTypesByConstName(t *testing.T) {
+	// every built-in type can be named in a tag
+	seen := make(map[Type]bool)
+	for _, typ := range typesByConstName {
+		seen[typ] = true
+	}
+	for typ := range typeTable {
+		assert.True(t, seen[typ], "missing %s", typ)
+	}
+}
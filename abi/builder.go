@@ -0,0 +1,63 @@
+package abi
+
+import (
+	"math/big"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+
+	"github.com/filecoin-project/go-filecoin/address"
+)
+
+// Builder assembles a parameter list one value at a time. Every value is
+// validated as it is added, the first error is kept and returned by Build.
+type Builder struct {
+	vals []*Value
+	err  error
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// AddAddress appends an Address value.
+func (b *Builder) AddAddress(addr address.Address) *Builder {
+	return b.add(NewAddress(addr))
+}
+
+// AddInteger appends an Integer value.
+func (b *Builder) AddInteger(i *big.Int) *Builder {
+	return b.add(NewInteger(i))
+}
+
+// AddBytes appends a Bytes value.
+func (b *Builder) AddBytes(data []byte) *Builder {
+	return b.add(NewBytes(data))
+}
+
+// AddString appends a String value.
+func (b *Builder) AddString(s string) *Builder {
+	return b.add(NewString(s))
+}
+
+func (b *Builder) add(av *Value) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	if err := av.Validate(); err != nil {
+		b.err = errors.Wrapf(err, "parameter %d", len(b.vals))
+		return b
+	}
+
+	b.vals = append(b.vals, av)
+	return b
+}
+
+// Build returns the values added so far, or the first error encountered.
+func (b *Builder) Build() ([]*Value, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.vals, nil
+}
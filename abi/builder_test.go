@@ -0,0 +1,43 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	addr := address.NewForTestGetter()()
+	vals, err := NewBuilder().
+		AddAddress(addr).
+		AddInteger(big.NewInt(7)).
+		AddString("flugzeug").
+		Build()
+	require.NoError(err)
+	require.Len(vals, 3)
+
+	exp, err := ToValues([]interface{}{addr, big.NewInt(7), "flugzeug"})
+	require.NoError(err)
+	for i := range exp {
+		assert.True(exp[i].Equals(vals[i]), "parameter %d: expected %s, got %s", i, exp[i], vals[i])
+	}
+}
+
+func TestBuilderKeepsFirstError(t *testing.T) {
+	assert := assert.New(t)
+
+	vals, err := NewBuilder().
+		AddBytes([]byte("beep")).
+		AddInteger(nil).
+		AddBytes(make([]byte, maxValueLength+1)).
+		Build()
+	assert.EqualError(err, "parameter 1: nil *big.Int value")
+	assert.Nil(vals)
+}
@@ -0,0 +1,73 @@
+package abi
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// DefaultByteOrder is the byte order SerializeWithByteOrder and
+// DeserializeWithByteOrder use when passed a nil order. Serialize and
+// Deserialize always use big endian, whatever its value, so that consensus
+// encodings can't change.
+var DefaultByteOrder binary.ByteOrder = binary.BigEndian
+
+// SerializeWithByteOrder works like Serialize, but encodes the fixed width
+// integer types Uint64, Int64 and Int256 in the given byte order, which must
+// be binary.BigEndian or binary.LittleEndian. It is meant for interoperating
+// with other tools; on chain values are big endian.
+func SerializeWithByteOrder(av *Value, order binary.ByteOrder) ([]byte, error) {
+	little, err := isLittleEndian(order)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := av.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	if little && isFixedWidthInteger(av.Type) {
+		reverseBytes(data)
+	}
+	return data, nil
+}
+
+// DeserializeWithByteOrder decodes data produced by SerializeWithByteOrder
+// with the same byte order.
+func DeserializeWithByteOrder(data []byte, t Type, order binary.ByteOrder) (*Value, error) {
+	little, err := isLittleEndian(order)
+	if err != nil {
+		return nil, err
+	}
+
+	if little && isFixedWidthInteger(t) {
+		data = append([]byte{}, data...)
+		reverseBytes(data)
+	}
+	return Deserialize(data, t)
+}
+
+func isLittleEndian(order binary.ByteOrder) (bool, error) {
+	if order == nil {
+		order = DefaultByteOrder
+	}
+
+	switch order {
+	case binary.BigEndian:
+		return false, nil
+	case binary.LittleEndian:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported byte order %s", order)
+	}
+}
+
+func isFixedWidthInteger(t Type) bool {
+	return t == Uint64 || t == Int64 || t == Int256
+}
+
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
@@ -0,0 +1,85 @@
+package abi
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteOrderRoundTrip(t *testing.T) {
+	vals := []*Value{
+		{Type: Uint64, Val: uint64(0x0102030405060708)},
+		{Type: Int64, Val: int64(-2)},
+		{Type: Int256, Val: big.NewInt(0x0102)},
+		{Type: String, Val: "flugzeug"},
+	}
+
+	for _, v := range vals {
+		for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian, nil} {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			data, err := SerializeWithByteOrder(v, order)
+			require.NoError(err)
+
+			out, err := DeserializeWithByteOrder(data, v.Type, order)
+			require.NoError(err)
+			assert.True(v.Equals(out), "%s: expected %s, got %s", order, v, out)
+		}
+	}
+}
+
+func TestByteOrderLayout(t *testing.T) {
+	assert := assert.New(t)
+
+	v := &Value{Type: Uint64, Val: uint64(0x0102030405060708)}
+
+	be, err := SerializeWithByteOrder(v, binary.BigEndian)
+	assert.NoError(err)
+	assert.Equal([]byte{1, 2, 3, 4, 5, 6, 7, 8}, be)
+
+	le, err := SerializeWithByteOrder(v, binary.LittleEndian)
+	assert.NoError(err)
+	assert.Equal([]byte{8, 7, 6, 5, 4, 3, 2, 1}, le)
+
+	// the consensus encoding doesn't change
+	data, err := v.Serialize()
+	assert.NoError(err)
+	assert.Equal(be, data)
+
+	// decoding with the wrong byte order silently yields a different number
+	out, err := DeserializeWithByteOrder(le, Uint64, binary.BigEndian)
+	assert.NoError(err)
+	assert.Equal(uint64(0x0807060504030201), out.Val)
+	assert.False(v.Equals(out))
+
+	// strings are not affected
+	s, err := SerializeWithByteOrder(NewString("ab"), binary.LittleEndian)
+	assert.NoError(err)
+	assert.Equal([]byte("ab"), s)
+}
+
+func TestDefaultByteOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(order binary.ByteOrder) { DefaultByteOrder = order }(DefaultByteOrder)
+	DefaultByteOrder = binary.LittleEndian
+
+	v := &Value{Type: Int64, Val: int64(1)}
+	data, err := SerializeWithByteOrder(v, nil)
+	assert.NoError(err)
+	assert.Equal([]byte{1, 0, 0, 0, 0, 0, 0, 0}, data)
+
+	// per call override
+	data, err = SerializeWithByteOrder(v, binary.BigEndian)
+	assert.NoError(err)
+	assert.Equal([]byte{0, 0, 0, 0, 0, 0, 0, 1}, data)
+
+	// Serialize ignores the default
+	data, err = v.Serialize()
+	assert.NoError(err)
+	assert.Equal([]byte{0, 0, 0, 0, 0, 0, 0, 1}, data)
+}
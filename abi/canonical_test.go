@@ -0,0 +1,324 @@
+package abi
+
+import (
+	"bufio"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"gx/ipfs/QmR8BauakNcBa3RbE4nbQu76PDiJgoQgz8AJdhJuiU4TAw/go-cid"
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+	"gx/ipfs/QmY5Grm8pJdiSSVsYxx4uNRgweY72EmYwuSDbRnbFok3iY/go-libp2p-peer"
+	mh "gx/ipfs/QmerPMzPk1mJVowm8KgmoknWa4yCYvvugMPsgWmDNUvDLW/go-multihash"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// canonicalValues returns a representative set of values for every type. The
+// encodings of these values are pinned in testdata/canonical.golden.
+func canonicalValues(t *testing.T) map[string]*Value {
+	hash, err := mh.Sum([]byte("canonical"), mh.SHA2_256, -1)
+	require.NoError(t, err)
+
+	large, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(t, ok)
+
+	return map[string]*Value{
+		"address":           {Type: Address, Val: address.MakeTestAddress("canonical")},
+		"attofil":           {Type: AttoFIL, Val: types.NewAttoFILFromFIL(1)},
+		"attofil-zero":      {Type: AttoFIL, Val: types.NewZeroAttoFIL()},
+		"bytesamount":       {Type: BytesAmount, Val: types.NewBytesAmount(1024)},
+		"channelid":         {Type: ChannelID, Val: types.NewChannelID(7)},
+		"blockheight":       {Type: BlockHeight, Val: types.NewBlockHeight(1000)},
+		"integer-zero":      {Type: Integer, Val: big.NewInt(0)},
+		"integer-one":       {Type: Integer, Val: big.NewInt(1)},
+		"integer-negative":  {Type: Integer, Val: big.NewInt(-258)},
+		"integer-large":     {Type: Integer, Val: large},
+		"bytes":             {Type: Bytes, Val: []byte{0xde, 0xad, 0xbe, 0xef}},
+		"bytes-empty":       {Type: Bytes, Val: []byte{}},
+		"string":            {Type: String, Val: "flugzeug"},
+		"string-empty":      {Type: String, Val: ""},
+		"uintarray":         {Type: UintArray, Val: []uint64{1, 2, 1000}},
+		"peerid":            {Type: PeerID, Val: peer.ID(hash)},
+		"sectorid":          {Type: SectorID, Val: uint64(300)},
+		"commitmentsmap":    {Type: CommitmentsMap, Val: map[string]types.Commitments{}},
+		"boolean-true":      {Type: Boolean, Val: true},
+		"boolean-false":     {Type: Boolean, Val: false},
+		"uint64":            {Type: Uint64, Val: uint64(0x0102030405060708)},
+		"cid":               {Type: Cid, Val: cid.NewCidV1(cid.Raw, hash)},
+		"int256":            {Type: Int256, Val: big.NewInt(255)},
+		"int256-zero":       {Type: Int256, Val: big.NewInt(0)},
+		"integer-max-int64": {Type: Integer, Val: big.NewInt(1<<63 - 1)},
+		"rational":          {Type: Rational, Val: big.NewRat(-1, 3)},
+		"float64":           {Type: Float64, Val: 1.5},
+		"int64":             {Type: Int64, Val: int64(-2)},
+		"addressset":        {Type: AddressSet, Val: []address.Address{address.MakeTestAddress("b"), address.MakeTestAddress("a")}},
+	}
+}
+
+func readGolden(t *testing.T, path string) map[string]string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	out := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 1 {
+			// values with an empty encoding
+			fields = append(fields, "")
+		}
+		require.Len(t, fields, 2, "malformed golden line: %q", line)
+		out[fields[0]] = fields[1]
+	}
+	require.NoError(t, scanner.Err())
+
+	return out
+}
+
+func TestCanonicalEncodingGolden(t *testing.T) {
+	golden := readGolden(t, "testdata/canonical.golden")
+	vals := canonicalValues(t)
+	require.Len(t, golden, len(vals))
+
+	for name, v := range vals {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			expected, ok := golden[name]
+			require.True(ok, "missing golden encoding for %s", name)
+
+			data, err := v.Serialize()
+			require.NoError(err)
+			assert.Equal(expected, hex.EncodeToString(data))
+
+			// encoding is deterministic and decoding then re-encoding is the identity
+			again, err := v.Serialize()
+			require.NoError(err)
+			assert.Equal(data, again)
+
+			canonical, err := Canonicalize(data, v.Type)
+			require.NoError(err)
+			assert.Equal(data, canonical)
+
+			_, err = DeserializeCanonical(data, v.Type)
+			assert.NoError(err)
+		})
+	}
+}
+
+func TestIntegerZeroIsNotEmpty(t *testing.T) {
+	assert := assert.New(t)
+
+	// Integer zero is encoded as a lone sign byte, so it can't be confused with
+	// an empty or missing value.
+	data, err := (&Value{Type: Integer, Val: big.NewInt(0)}).Serialize()
+	assert.NoError(err)
+	assert.Equal([]byte{0x00}, data)
+
+	data, err = (&Value{Type: Integer, Val: new(big.Int).SetBytes([]byte{0, 0, 0})}).Serialize()
+	assert.NoError(err)
+	assert.Equal([]byte{0x00}, data)
+}
+
+func TestCanonicalize(t *testing.T) {
+	assert := assert.New(t)
+
+	// leading zero bytes in the magnitude are dropped
+	canonical, err := Canonicalize([]byte{0x00, 0x00, 0x01}, Integer)
+	assert.NoError(err)
+	assert.Equal([]byte{0x00, 0x01}, canonical)
+
+	_, err = Canonicalize([]byte{0x02}, Integer)
+	assert.Error(err)
+}
+
+func TestDeserializeCanonical(t *testing.T) {
+	assert := assert.New(t)
+
+	v, err := DeserializeCanonical([]byte{0x00, 0x01}, Integer)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(1), v.Val)
+
+	// the lenient decoder accepts these
+	for _, data := range [][]byte{{0x00, 0x00, 0x01}, {0x01, 0x00, 0x01}, {0x00, 0x00}, {0x01}} {
+		_, err := Deserialize(data, Integer)
+		assert.NoError(err)
+	}
+
+	_, err = DeserializeCanonical([]byte{0x00, 0x00, 0x01}, Integer)
+	assert.EqualError(err, "non canonical integer encoding: leading zero byte")
+
+	_, err = DeserializeCanonical([]byte{0x01, 0x00, 0x01}, Integer)
+	assert.EqualError(err, "non canonical integer encoding: leading zero byte")
+
+	_, err = DeserializeCanonical([]byte{0x00, 0x00}, Integer)
+	assert.EqualError(err, "non canonical integer encoding: leading zero byte")
+
+	_, err = DeserializeCanonical([]byte{0x01}, Integer)
+	assert.EqualError(err, "non canonical integer encoding: negative zero")
+
+	// other types are checked by encoding the value again
+	_, err = DeserializeCanonical([]byte{0x00}, AttoFIL)
+	assert.NoError(err)
+	_, err = DeserializeCanonical([]byte{0x00, 0x00}, AttoFIL)
+	assert.EqualError(err, "non canonical *types.AttoFIL encoding")
+
+	_, err = DeserializeCanonical([]byte{0x02}, Integer)
+	assert.Error(err)
+}
+
+func TestDeserializeStrictUTF8(t *testing.T) {
+	assert := assert.New(t)
+
+	v, err := DeserializeStrict([]byte("flugzeug ✈"), String)
+	assert.NoError(err)
+	assert.Equal("flugzeug ✈", v.Val)
+
+	for name, data := range map[string][]byte{
+		"lone surrogate":      {0xed, 0xa0, 0x80},
+		"truncated multibyte": []byte("✈")[:2],
+		"invalid start byte":  {0xff},
+	} {
+		_, err := DeserializeStrict(data, String)
+		assert.EqualError(err, "invalid string encoding: not valid utf-8", name)
+
+		// the lenient decoder keeps the raw bytes
+		v, err := Deserialize(data, String)
+		assert.NoError(err, name)
+		assert.Equal(string(data), v.Val)
+	}
+
+	_, err = DeserializeStrict([]byte{0x00, 0x00, 0x01}, Integer)
+	assert.EqualError(err, "non canonical integer encoding: leading zero byte")
+}
+
+func TestDeserializeStrictWithSign(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := (&Value{Type: Integer, Val: big.NewInt(-5)}).Serialize()
+	assert.NoError(err)
+
+	v, err := DeserializeStrictWithSign(data, Integer, Signed)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(-5), v.Val)
+
+	_, err = DeserializeStrictWithSign(data, Integer, Unsigned)
+	assert.EqualError(err, "expected a non-negative *big.Int, got -5")
+
+	data, err = (&Value{Type: Integer, Val: big.NewInt(5)}).Serialize()
+	assert.NoError(err)
+	v, err = DeserializeStrictWithSign(data, Integer, Unsigned)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(5), v.Val)
+
+	data, err = (&Value{Type: Int64, Val: int64(-1)}).Serialize()
+	assert.NoError(err)
+	_, err = DeserializeStrictWithSign(data, Int64, Unsigned)
+	assert.EqualError(err, "expected a non-negative int64, got -1")
+
+	// types without a sign are unaffected
+	v, err = DeserializeStrictWithSign([]byte("foo"), String, Unsigned)
+	assert.NoError(err)
+	assert.Equal("foo", v.Val)
+}
+
+func TestDeserializeWith(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		assert := assert.New(t)
+
+		v, err := DeserializeWith([]byte{0x05, 0x00}, SectorID, DecodeOptions{})
+		assert.NoError(err)
+		assert.Equal(uint64(5), v.Val)
+	})
+
+	t.Run("canonical", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := DeserializeWith([]byte{0x00, 0x00, 0x01}, Integer, DecodeOptions{Canonical: true})
+		assert.EqualError(err, "non canonical integer encoding: leading zero byte")
+	})
+
+	t.Run("utf8", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := DeserializeWith([]byte{0xff}, String, DecodeOptions{ValidateUTF8: true})
+		assert.EqualError(err, "invalid string encoding: not valid utf-8")
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := DeserializeWith([]byte("flugzeug"), String, DecodeOptions{MaxBytes: 4})
+		assert.EqualError(err, "string encoding too long: 8 bytes exceeds limit of 4")
+
+		_, err = DeserializeWith([]byte("flug"), String, DecodeOptions{MaxBytes: 4})
+		assert.NoError(err)
+	})
+
+	t.Run("trailing", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := DeserializeWith([]byte{0x05, 0x00}, SectorID, DecodeOptions{RejectTrailing: true})
+		assert.Equal(ErrTrailingData, errors.Cause(err))
+		assert.EqualError(err, "1 bytes left after uint64 value: trailing data")
+
+		_, err = DeserializeWith([]byte{0x05}, SectorID, DecodeOptions{RejectTrailing: true})
+		assert.NoError(err)
+
+		_, err = DeserializeWith([]byte{0x82, 0x01, 0x02, 0x00}, UintArray, DecodeOptions{RejectTrailing: true})
+		assert.Equal(ErrTrailingData, errors.Cause(err))
+		assert.EqualError(err, "1 bytes left after []uint64 value: trailing data")
+	})
+
+	t.Run("non canonical is not trailing", func(t *testing.T) {
+		assert := assert.New(t)
+
+		// leading zero bytes in the magnitude
+		v, err := DeserializeWith([]byte{0x00, 0x00, 0x05}, Integer, DecodeOptions{RejectTrailing: true})
+		assert.NoError(err)
+		assert.Equal(0, big.NewInt(5).Cmp(v.Val.(*big.Int)))
+
+		// the cbor argument 1 encoded in two bytes
+		v, err = DeserializeWith([]byte{0x82, 0x18, 0x01, 0x02}, UintArray, DecodeOptions{RejectTrailing: true})
+		assert.NoError(err)
+		assert.Equal([]uint64{1, 2}, v.Val)
+
+		// both are left to the Canonical option
+		_, err = DeserializeWith([]byte{0x00, 0x00, 0x05}, Integer, DecodeOptions{Canonical: true, RejectTrailing: true})
+		assert.EqualError(err, "non canonical integer encoding: leading zero byte")
+		_, err = DeserializeWith([]byte{0x82, 0x18, 0x01, 0x02}, UintArray, DecodeOptions{Canonical: true, RejectTrailing: true})
+		assert.Error(err)
+		assert.NotEqual(ErrTrailingData, errors.Cause(err))
+	})
+
+	t.Run("strict profile", func(t *testing.T) {
+		assert := assert.New(t)
+
+		strict := DecodeOptions{Canonical: true, ValidateUTF8: true, MaxBytes: 16, RejectTrailing: true}
+
+		v, err := DeserializeWith([]byte("flugzeug ✈"), String, strict)
+		assert.NoError(err)
+		assert.Equal("flugzeug ✈", v.Val)
+
+		_, err = DeserializeWith([]byte{0x05, 0x00}, SectorID, strict)
+		assert.Error(err)
+		_, err = DeserializeWith(make([]byte, 17), Bytes, strict)
+		assert.Error(err)
+		_, err = DeserializeWith([]byte{0xed, 0xa0, 0x80}, String, strict)
+		assert.Error(err)
+	})
+}
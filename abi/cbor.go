@@ -0,0 +1,444 @@
+package abi
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+)
+
+// CBOR major types and tags used by the value encoding, see RFC 7049.
+const (
+	cborMajorUint   = 0
+	cborMajorNegInt = 1
+	cborMajorBytes  = 2
+	cborMajorText   = 3
+	cborMajorArray  = 4
+	cborMajorMap    = 5
+	cborMajorTag    = 6
+	cborMajorSimple = 7
+
+	cborTagPosBignum = 2
+	cborTagNegBignum = 3
+
+	cborSimpleFalse = 20
+	cborSimpleTrue  = 21
+)
+
+var errCborTruncated = fmt.Errorf("cbor: unexpected end of input")
+
+// MarshalCBOR encodes the value as canonical CBOR. The encoding is a two
+// element array of the type and the payload, which makes it self describing.
+// Integers that don't fit into a CBOR int are encoded as bignums (tags 2 and 3).
+func (av *Value) MarshalCBOR() ([]byte, error) {
+	return appendCborValue(nil, av)
+}
+
+// UnmarshalCBOR decodes a value produced by MarshalCBOR and checks that it
+// carries the given type. Like Deserialize it never aliases data and applies
+// MaxIntegerBits.
+func UnmarshalCBOR(data []byte, t Type) (*Value, error) {
+	if t == Invalid {
+		return nil, ErrInvalidType
+	}
+
+	av, rest, err := readCborValue(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("cbor: %d trailing bytes after value", len(rest))
+	}
+
+	if av.Type != t {
+		return nil, fmt.Errorf("cbor: expected value of type %s, got %s", t, av.Type)
+	}
+
+	return av, nil
+}
+
+// MarshalCBORArray encodes a list of values as a CBOR array of their
+// MarshalCBOR encodings.
+func MarshalCBORArray(vals []*Value) ([]byte, error) {
+	buf := appendCborHeader(nil, cborMajorArray, uint64(len(vals)))
+	for i, v := range vals {
+		var err error
+		buf, err = appendCborValue(buf, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "array element %d", i)
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalCBORArray decodes a list of values produced by MarshalCBORArray.
+// Since every element carries its type no type information is needed. Empty
+// arrays are normalized to nil.
+func UnmarshalCBORArray(data []byte) ([]*Value, error) {
+	major, n, data, err := readCborHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if major != cborMajorArray {
+		return nil, fmt.Errorf("cbor: expected an array, got major type %d", major)
+	}
+	// every element takes at least one byte, don't trust n any further
+	if n > uint64(len(data)) {
+		return nil, errCborTruncated
+	}
+
+	var out []*Value
+	for i := uint64(0); i < n; i++ {
+		var av *Value
+		av, data, err = readCborValue(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "array element %d", i)
+		}
+		out = append(out, av)
+	}
+
+	if len(data) != 0 {
+		return nil, fmt.Errorf("cbor: %d trailing bytes after array", len(data))
+	}
+	return out, nil
+}
+
+func appendCborValue(buf []byte, av *Value) ([]byte, error) {
+	if av == nil {
+		return nil, ErrNilValue
+	}
+
+	buf = appendCborHeader(buf, cborMajorArray, 2)
+	buf = appendCborHeader(buf, cborMajorUint, uint64(av.Type))
+
+	switch av.Type {
+	case Invalid:
+		return nil, ErrInvalidType
+	case Integer:
+		intgr, ok := av.Val.(*big.Int)
+		if !ok {
+			return nil, &typeError{&big.Int{}, av.Val}
+		}
+
+		return appendCborBigInt(buf, intgr), nil
+	case Bytes:
+		b, ok := av.Val.([]byte)
+		if !ok {
+			return nil, &typeError{[]byte{}, av.Val}
+		}
+
+		buf = appendCborHeader(buf, cborMajorBytes, uint64(len(b)))
+		return append(buf, b...), nil
+	case String:
+		s, ok := av.Val.(string)
+		if !ok {
+			return nil, &typeError{"", av.Val}
+		}
+
+		buf = appendCborHeader(buf, cborMajorText, uint64(len(s)))
+		return append(buf, s...), nil
+	case Boolean:
+		b, ok := av.Val.(bool)
+		if !ok {
+			return nil, &typeError{false, av.Val}
+		}
+
+		if b {
+			return appendCborHeader(buf, cborMajorSimple, cborSimpleTrue), nil
+		}
+		return appendCborHeader(buf, cborMajorSimple, cborSimpleFalse), nil
+	case SectorID, Uint64:
+		n, ok := av.Val.(uint64)
+		if !ok {
+			return nil, &typeError{uint64(0), av.Val}
+		}
+
+		return appendCborHeader(buf, cborMajorUint, n), nil
+	default:
+		// everything else is carried as the raw serialized bytes
+		data, err := av.Serialize()
+		if err != nil {
+			return nil, err
+		}
+
+		buf = appendCborHeader(buf, cborMajorBytes, uint64(len(data)))
+		return append(buf, data...), nil
+	}
+}
+
+func readCborValue(data []byte) (*Value, []byte, error) {
+	major, n, data, err := readCborHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if major != cborMajorArray || n != 2 {
+		return nil, nil, fmt.Errorf("cbor: expected a two element array")
+	}
+
+	major, n, data, err = readCborHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if major != cborMajorUint {
+		return nil, nil, fmt.Errorf("cbor: expected an unsigned int type tag")
+	}
+
+	t := Type(n)
+	switch t {
+	case Invalid:
+		return nil, nil, ErrInvalidType
+	case Integer:
+		intgr, rest, err := readCborBigInt(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkIntegerBits(intgr); err != nil {
+			return nil, nil, err
+		}
+
+		return &Value{Type: t, Val: intgr}, rest, nil
+	case Bytes:
+		b, rest, err := readCborString(data, cborMajorBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// like Deserialize, never alias the input
+		return &Value{Type: t, Val: append([]byte{}, b...)}, rest, nil
+	case String:
+		s, rest, err := readCborString(data, cborMajorText)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &Value{Type: t, Val: string(s)}, rest, nil
+	case Boolean:
+		major, n, rest, err := readCborHeader(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if major != cborMajorSimple || (n != cborSimpleFalse && n != cborSimpleTrue) {
+			return nil, nil, fmt.Errorf("cbor: expected a boolean")
+		}
+
+		return &Value{Type: t, Val: n == cborSimpleTrue}, rest, nil
+	case SectorID, Uint64:
+		major, n, rest, err := readCborHeader(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if major != cborMajorUint {
+			return nil, nil, fmt.Errorf("cbor: expected an unsigned int")
+		}
+
+		return &Value{Type: t, Val: n}, rest, nil
+	default:
+		raw, rest, err := readCborString(data, cborMajorBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		av, err := Deserialize(raw, t)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return av, rest, nil
+	}
+}
+
+func appendCborHeader(buf []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(buf, m|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, m|24, byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, m|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(buf, m|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return append(buf, m|27,
+			byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+			byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// readCborHeader reads the initial byte and argument of a data item. Only the
+// shortest possible encoding of the argument is accepted.
+func readCborHeader(data []byte) (byte, uint64, []byte, error) {
+	if len(data) == 0 {
+		return 0, 0, nil, errCborTruncated
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	if info < 24 {
+		return major, uint64(info), data, nil
+	}
+	if info > 27 {
+		return 0, 0, nil, fmt.Errorf("cbor: unsupported additional info %d", info)
+	}
+
+	size := 1 << (info - 24)
+	if len(data) < size {
+		return 0, 0, nil, errCborTruncated
+	}
+
+	var n uint64
+	for _, b := range data[:size] {
+		n = n<<8 | uint64(b)
+	}
+
+	min := uint64(24)
+	if size > 1 {
+		min = 1 << (uint(size) * 4)
+	}
+	if n < min {
+		return 0, 0, nil, fmt.Errorf("cbor: non canonical argument encoding")
+	}
+
+	return major, n, data[size:], nil
+}
+
+// skipCborItem returns the input following the first data item. Unlike
+// readCborHeader it accepts non canonical argument encodings, since it only
+// measures the item.
+func skipCborItem(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errCborTruncated
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(data) < size {
+			return nil, errCborTruncated
+		}
+		for _, b := range data[:size] {
+			n = n<<8 | uint64(b)
+		}
+		data = data[size:]
+	default:
+		return nil, fmt.Errorf("cbor: unsupported additional info %d", info)
+	}
+
+	switch major {
+	case cborMajorBytes, cborMajorText:
+		if uint64(len(data)) < n {
+			return nil, errCborTruncated
+		}
+		return data[n:], nil
+	case cborMajorArray, cborMajorMap:
+		// every item takes at least one byte, don't trust n any further
+		if n > uint64(len(data)) {
+			return nil, errCborTruncated
+		}
+		if major == cborMajorMap {
+			n *= 2
+		}
+
+		var err error
+		for i := uint64(0); i < n; i++ {
+			if data, err = skipCborItem(data); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	case cborMajorTag:
+		return skipCborItem(data)
+	default:
+		// integers, simple values and floats have no content
+		return data, nil
+	}
+}
+
+func readCborString(data []byte, major byte) ([]byte, []byte, error) {
+	m, n, data, err := readCborHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m != major {
+		return nil, nil, fmt.Errorf("cbor: expected major type %d, got %d", major, m)
+	}
+	if uint64(len(data)) < n {
+		return nil, nil, errCborTruncated
+	}
+
+	return data[:n], data[n:], nil
+}
+
+func appendCborBigInt(buf []byte, intgr *big.Int) []byte {
+	if intgr.Sign() >= 0 {
+		if intgr.IsUint64() {
+			return appendCborHeader(buf, cborMajorUint, intgr.Uint64())
+		}
+
+		b := intgr.Bytes()
+		buf = appendCborHeader(buf, cborMajorTag, cborTagPosBignum)
+		buf = appendCborHeader(buf, cborMajorBytes, uint64(len(b)))
+		return append(buf, b...)
+	}
+
+	// negative values are encoded as -1 - n
+	n := new(big.Int).Neg(intgr)
+	n.Sub(n, big.NewInt(1))
+	if n.IsUint64() {
+		return appendCborHeader(buf, cborMajorNegInt, n.Uint64())
+	}
+
+	b := n.Bytes()
+	buf = appendCborHeader(buf, cborMajorTag, cborTagNegBignum)
+	buf = appendCborHeader(buf, cborMajorBytes, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func readCborBigInt(data []byte) (*big.Int, []byte, error) {
+	major, n, rest, err := readCborHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case cborMajorUint:
+		return new(big.Int).SetUint64(n), rest, nil
+	case cborMajorNegInt:
+		intgr := new(big.Int).SetUint64(n)
+		intgr.Add(intgr, big.NewInt(1))
+		return intgr.Neg(intgr), rest, nil
+	case cborMajorTag:
+		if n != cborTagPosBignum && n != cborTagNegBignum {
+			return nil, nil, fmt.Errorf("cbor: unexpected tag %d", n)
+		}
+
+		b, rest, err := readCborString(rest, cborMajorBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		intgr := new(big.Int).SetBytes(b)
+		if len(b) == 0 || b[0] == 0 || intgr.IsUint64() {
+			return nil, nil, fmt.Errorf("cbor: non canonical bignum encoding")
+		}
+
+		if n == cborTagNegBignum {
+			intgr.Add(intgr, big.NewInt(1))
+			intgr.Neg(intgr)
+		}
+		return intgr, rest, nil
+	default:
+		return nil, nil, fmt.Errorf("cbor: expected an integer, got major type %d", major)
+	}
+}
@@ -0,0 +1,203 @@
+package abi
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCBORRoundTrip(t *testing.T) {
+	addrGetter := address.NewForTestGetter()
+
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	cases := map[string]*Value{
+		"address":      {Type: Address, Val: addrGetter()},
+		"string":       {Type: String, Val: "flugzeug"},
+		"empty string": {Type: String, Val: ""},
+		"bytes":        {Type: Bytes, Val: []byte("beep")},
+		"small int":    {Type: Integer, Val: big.NewInt(17)},
+		"negative int": {Type: Integer, Val: big.NewInt(-500)},
+		"huge int":     {Type: Integer, Val: huge},
+		"huge neg int": {Type: Integer, Val: new(big.Int).Neg(huge)},
+		"boolean":      {Type: Boolean, Val: true},
+		"sector id":    {Type: SectorID, Val: uint64(1234)},
+		"uint64":       {Type: Uint64, Val: uint64(1 << 40)},
+	}
+
+	for tname, tcase := range cases {
+		t.Run(tname, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			data, err := tcase.MarshalCBOR()
+			require.NoError(err)
+
+			out, err := UnmarshalCBOR(data, tcase.Type)
+			require.NoError(err)
+			assert.Equal(tcase.Type, out.Type)
+
+			if intgr, ok := tcase.Val.(*big.Int); ok {
+				assert.Equal(0, intgr.Cmp(out.Val.(*big.Int)))
+			} else {
+				assert.Equal(tcase.Val, out.Val)
+			}
+		})
+	}
+}
+
+func TestCBORMatchesRawRoundTrip(t *testing.T) {
+	addrGetter := address.NewForTestGetter()
+
+	for _, av := range []*Value{
+		{Type: Address, Val: addrGetter()},
+		{Type: String, Val: "mr rogers"},
+	} {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		raw, err := av.Serialize()
+		require.NoError(err)
+		rawOut, err := Deserialize(raw, av.Type)
+		require.NoError(err)
+
+		data, err := av.MarshalCBOR()
+		require.NoError(err)
+		cborOut, err := UnmarshalCBOR(data, av.Type)
+		require.NoError(err)
+
+		assert.Equal(rawOut, cborOut)
+	}
+}
+
+func TestCBORIntegerEncoding(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := (&Value{Type: Integer, Val: big.NewInt(1)}).MarshalCBOR()
+	assert.NoError(err)
+	assert.Equal([]byte{0x82, 0x06, 0x01}, data)
+
+	data, err = (&Value{Type: Integer, Val: big.NewInt(-1)}).MarshalCBOR()
+	assert.NoError(err)
+	assert.Equal([]byte{0x82, 0x06, 0x20}, data)
+
+	// 2^64 does not fit into a CBOR int and needs a positive bignum tag
+	data, err = (&Value{Type: Integer, Val: new(big.Int).Lsh(big.NewInt(1), 64)}).MarshalCBOR()
+	assert.NoError(err)
+	assert.Equal([]byte{0x82, 0x06, 0xc2, 0x49, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}, data)
+
+	// -2^64 - 1 needs a negative bignum tag
+	n := new(big.Int).Lsh(big.NewInt(1), 64)
+	data, err = (&Value{Type: Integer, Val: n.Neg(n).Sub(n, big.NewInt(1))}).MarshalCBOR()
+	assert.NoError(err)
+	assert.Equal([]byte{0x82, 0x06, 0xc3, 0x49, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}, data)
+}
+
+func TestUnmarshalCBORFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := (&Value{Type: String, Val: "foo"}).MarshalCBOR()
+	assert.NoError(err)
+
+	_, err = UnmarshalCBOR(data, Bytes)
+	assert.EqualError(err, "cbor: expected value of type []byte, got string")
+
+	_, err = UnmarshalCBOR(data[:len(data)-1], String)
+	assert.Equal(errCborTruncated, err)
+
+	_, err = UnmarshalCBOR(append(data, 0), String)
+	assert.EqualError(err, "cbor: 1 trailing bytes after value")
+
+	_, err = UnmarshalCBOR(data, Invalid)
+	assert.Equal(ErrInvalidType, err)
+
+	// the integer 1 with a needlessly long argument encoding
+	_, err = UnmarshalCBOR([]byte{0x82, 0x06, 0x18, 0x01}, Integer)
+	assert.EqualError(err, "cbor: non canonical argument encoding")
+}
+
+func TestUnmarshalCBORDoesNotAlias(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	data, err := (&Value{Type: Bytes, Val: []byte("beep")}).MarshalCBOR()
+	require.NoError(err)
+
+	v, err := UnmarshalCBOR(data, Bytes)
+	require.NoError(err)
+
+	for i := range data {
+		data[i] = 0
+	}
+	assert.Equal([]byte("beep"), v.Val)
+}
+
+func TestUnmarshalCBORIntegerBitLimit(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tooLarge := new(big.Int).Lsh(big.NewInt(1), uint(MaxIntegerBits))
+	data, err := (&Value{Type: Integer, Val: tooLarge}).MarshalCBOR()
+	require.NoError(err)
+
+	_, err = UnmarshalCBOR(data, Integer)
+	assert.EqualError(err, fmt.Sprintf("integer too large: %d bits exceeds limit of %d", MaxIntegerBits+1, MaxIntegerBits))
+
+	_, err = UnmarshalCBORArray(append([]byte{0x81}, data...))
+	assert.Error(err)
+}
+
+func TestCBORArrayRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	data, err := MarshalCBORArray(nil)
+	require.NoError(err)
+	assert.Equal([]byte{0x80}, data)
+
+	out, err := UnmarshalCBORArray(data)
+	require.NoError(err)
+	assert.Nil(out)
+
+	vals := []*Value{
+		{Type: Address, Val: address.NewForTestGetter()()},
+		{Type: Integer, Val: big.NewInt(-500)},
+		{Type: String, Val: "flugzeug"},
+		{Type: Boolean, Val: true},
+	}
+	data, err = MarshalCBORArray(vals)
+	require.NoError(err)
+	assert.Equal(byte(0x84), data[0])
+
+	out, err = UnmarshalCBORArray(data)
+	require.NoError(err)
+	assert.Nil(DiffValues(vals, out))
+}
+
+func TestCBORArrayFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := MarshalCBORArray([]*Value{{Type: String, Val: "foo"}, nil})
+	assert.EqualError(err, "array element 1: nil value")
+
+	data, err := MarshalCBORArray([]*Value{{Type: String, Val: "foo"}})
+	assert.NoError(err)
+
+	_, err = UnmarshalCBORArray(data[:len(data)-1])
+	assert.EqualError(err, "array element 0: cbor: unexpected end of input")
+
+	_, err = UnmarshalCBORArray(append(data, 0))
+	assert.EqualError(err, "cbor: 1 trailing bytes after array")
+
+	_, err = UnmarshalCBORArray([]byte{0x63, 'f', 'o', 'o'})
+	assert.EqualError(err, "cbor: expected an array, got major type 3")
+
+	// an array header claiming 2^32 elements
+	_, err = UnmarshalCBORArray([]byte{0x9a, 0xff, 0xff, 0xff, 0xff})
+	assert.Equal(errCborTruncated, err)
+}
@@ -0,0 +1,95 @@
+package abi
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+)
+
+// ToValuesCoerce converts go values to abi values of the given types. Unlike
+// ToValues it accepts any go integer kind for Integer, Int256, SectorID, Uint64
+// and Int64, and named types whose underlying type is []byte or string for Bytes
+// and String. Conversions must be lossless, so negative numbers are rejected
+// for unsigned types and numbers outside the int64 range for Int64.
+func ToValuesCoerce(vals []interface{}, types []Type) ([]*Value, error) {
+	if len(vals) != len(types) {
+		return nil, fmt.Errorf("expected %d parameters, but got %d", len(types), len(vals))
+	}
+	if len(vals) == 0 {
+		return nil, nil
+	}
+
+	out := make([]*Value, 0, len(vals))
+	for i, v := range vals {
+		val, err := coerce(v, types[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "parameter %d", i)
+		}
+		out = append(out, &Value{Type: types[i], Val: val})
+	}
+	return out, nil
+}
+
+func coerce(v interface{}, t Type) (interface{}, error) {
+	rt, ok := typeTable[t]
+	if !ok {
+		if t == Invalid {
+			return nil, ErrInvalidType
+		}
+		return nil, fmt.Errorf("unrecognized Type: %d", t)
+	}
+	if v == nil {
+		return nil, fmt.Errorf("cannot coerce nil to %s", t)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Type() == rt {
+		if rt.Kind() == reflect.Ptr && rv.IsNil() {
+			return nil, fmt.Errorf("cannot use nil %T", v)
+		}
+		return v, nil
+	}
+
+	switch t {
+	case Integer, Int256, SectorID, Uint64, Int64:
+		var intgr *big.Int
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			intgr = big.NewInt(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			intgr = new(big.Int).SetUint64(rv.Uint())
+		default:
+			return nil, fmt.Errorf("cannot coerce %T to %s", v, t)
+		}
+
+		switch t {
+		case Int64:
+			if !intgr.IsInt64() {
+				return nil, fmt.Errorf("cannot coerce %s to %s: out of range", intgr, t)
+			}
+			return intgr.Int64(), nil
+		case Integer:
+			return intgr, nil
+		}
+
+		if intgr.Sign() < 0 {
+			return nil, fmt.Errorf("cannot coerce negative %s to %s", intgr, t)
+		}
+		if t == SectorID || t == Uint64 {
+			return intgr.Uint64(), nil
+		}
+		return intgr, nil
+	case Bytes, String:
+		if !rv.Type().ConvertibleTo(rt) || rv.Kind() != rt.Kind() {
+			return nil, fmt.Errorf("cannot coerce %T to %s", v, t)
+		}
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+			return nil, fmt.Errorf("cannot coerce %T to %s", v, t)
+		}
+		return rv.Convert(rt).Interface(), nil
+	default:
+		return nil, fmt.Errorf("cannot coerce %T to %s", v, t)
+	}
+}
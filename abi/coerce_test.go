@@ -0,0 +1,73 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type namedBytes []byte
+
+type namedString string
+
+func TestToValuesCoerce(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	vals, err := ToValuesCoerce(
+		[]interface{}{-5, int64(1 << 40), uint(7), 3, int8(9), namedBytes{1, 2}, namedString("foo"), big.NewInt(1), "bar", int32(-3)},
+		[]Type{Integer, Integer, Integer, SectorID, Uint64, Bytes, String, Integer, String, Int64},
+	)
+	require.NoError(err)
+
+	assert.Equal([]*Value{
+		{Type: Integer, Val: big.NewInt(-5)},
+		{Type: Integer, Val: big.NewInt(1 << 40)},
+		{Type: Integer, Val: big.NewInt(7)},
+		{Type: SectorID, Val: uint64(3)},
+		{Type: Uint64, Val: uint64(9)},
+		{Type: Bytes, Val: []byte{1, 2}},
+		{Type: String, Val: "foo"},
+		{Type: Integer, Val: big.NewInt(1)},
+		{Type: String, Val: "bar"},
+		{Type: Int64, Val: int64(-3)},
+	}, vals)
+
+	// the coerced values serialize like natively constructed ones
+	for _, v := range vals {
+		assert.NoError(v.Validate())
+	}
+}
+
+func TestToValuesCoerceFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ToValuesCoerce([]interface{}{1, -1}, []Type{SectorID, SectorID})
+	assert.EqualError(err, "parameter 1: cannot coerce negative -1 to uint64")
+
+	_, err = ToValuesCoerce([]interface{}{int64(-1)}, []Type{Int256})
+	assert.EqualError(err, "parameter 0: cannot coerce negative -1 to int256")
+
+	_, err = ToValuesCoerce([]interface{}{uint64(1 << 63)}, []Type{Int64})
+	assert.EqualError(err, "parameter 0: cannot coerce 9223372036854775808 to int64: out of range")
+
+	_, err = ToValuesCoerce([]interface{}{1.5}, []Type{Integer})
+	assert.EqualError(err, "parameter 0: cannot coerce float64 to *big.Int")
+
+	_, err = ToValuesCoerce([]interface{}{"foo"}, []Type{Bytes})
+	assert.EqualError(err, "parameter 0: cannot coerce string to []byte")
+
+	_, err = ToValuesCoerce([]interface{}{[]int{1}}, []Type{Bytes})
+	assert.EqualError(err, "parameter 0: cannot coerce []int to []byte")
+
+	_, err = ToValuesCoerce([]interface{}{nil}, []Type{Integer})
+	assert.EqualError(err, "parameter 0: cannot coerce nil to *big.Int")
+
+	_, err = ToValuesCoerce([]interface{}{(*big.Int)(nil)}, []Type{Integer})
+	assert.EqualError(err, "parameter 0: cannot use nil *big.Int")
+
+	_, err = ToValuesCoerce([]interface{}{1}, nil)
+	assert.EqualError(err, "expected 0 parameters, but got 1")
+}
@@ -0,0 +1,88 @@
+package abi
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/filecoin-project/go-filecoin/address"
+)
+
+// Compare orders two values of the same type, returning -1, 0 or 1 like
+// bytes.Compare. Bytes, String and Address values are ordered
+// lexicographically, numbers by value and false before true. It errors if the
+// types differ, the type has no ordering or a Float64 is NaN.
+func (av *Value) Compare(other *Value) (int, error) {
+	if av == nil || other == nil {
+		return 0, ErrNilValue
+	}
+	if av.Type != other.Type {
+		return 0, fmt.Errorf("cannot compare values of type %s and %s", av.Type, other.Type)
+	}
+	if err := av.Validate(); err != nil {
+		return 0, err
+	}
+	if err := other.Validate(); err != nil {
+		return 0, err
+	}
+
+	switch a := av.Val.(type) {
+	case []byte:
+		return bytes.Compare(a, other.Val.([]byte)), nil
+	case string:
+		return strings.Compare(a, other.Val.(string)), nil
+	case address.Address:
+		b := other.Val.(address.Address)
+		return bytes.Compare(a.Bytes(), b.Bytes()), nil
+	case *big.Int:
+		return a.Cmp(other.Val.(*big.Int)), nil
+	case *big.Rat:
+		return a.Cmp(other.Val.(*big.Rat)), nil
+	case uint64:
+		b := other.Val.(uint64)
+		switch {
+		case a < b:
+			return -1, nil
+		case a > b:
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	case int64:
+		b := other.Val.(int64)
+		switch {
+		case a < b:
+			return -1, nil
+		case a > b:
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	case float64:
+		b := other.Val.(float64)
+		switch {
+		case math.IsNaN(a) || math.IsNaN(b):
+			return 0, fmt.Errorf("cannot compare NaN")
+		case a < b:
+			return -1, nil
+		case a > b:
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	case bool:
+		b := other.Val.(bool)
+		switch {
+		case a == b:
+			return 0, nil
+		case b:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	default:
+		return 0, fmt.Errorf("values of type %s are not ordered", av.Type)
+	}
+}
@@ -0,0 +1,80 @@
+package abi
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareSort(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	vals := []*Value{
+		{Type: Integer, Val: big.NewInt(17)},
+		{Type: Integer, Val: big.NewInt(-300)},
+		{Type: Integer, Val: big.NewInt(0)},
+		{Type: Integer, Val: new(big.Int).Lsh(big.NewInt(1), 100)},
+		{Type: Integer, Val: big.NewInt(-1)},
+	}
+
+	sort.Slice(vals, func(i, j int) bool {
+		c, err := vals[i].Compare(vals[j])
+		require.NoError(err)
+		return c < 0
+	})
+
+	var out []string
+	for _, v := range vals {
+		out = append(out, v.Val.(*big.Int).String())
+	}
+	assert.Equal([]string{"-300", "-1", "0", "17", "1267650600228229401496703205376"}, out)
+}
+
+func TestCompare(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, tcase := range []struct {
+		a, b *Value
+		exp  int
+	}{
+		{&Value{Type: Bytes, Val: []byte{1, 2}}, &Value{Type: Bytes, Val: []byte{1, 3}}, -1},
+		{&Value{Type: Bytes, Val: []byte{1, 2}}, &Value{Type: Bytes, Val: []byte{1}}, 1},
+		{&Value{Type: String, Val: "b"}, &Value{Type: String, Val: "a"}, 1},
+		{&Value{Type: String, Val: "a"}, &Value{Type: String, Val: "a"}, 0},
+		{&Value{Type: SectorID, Val: uint64(1)}, &Value{Type: SectorID, Val: uint64(2)}, -1},
+		{&Value{Type: Boolean, Val: true}, &Value{Type: Boolean, Val: false}, 1},
+		{&Value{Type: Int64, Val: int64(-5)}, &Value{Type: Int64, Val: int64(3)}, -1},
+		{&Value{Type: Int64, Val: int64(7)}, &Value{Type: Int64, Val: int64(7)}, 0},
+		{&Value{Type: Float64, Val: 2.5}, &Value{Type: Float64, Val: -1.0}, 1},
+		{&Value{Type: Float64, Val: math.Copysign(0, -1)}, &Value{Type: Float64, Val: 0.0}, 0},
+		{&Value{Type: Rational, Val: big.NewRat(1, 3)}, &Value{Type: Rational, Val: big.NewRat(1, 2)}, -1},
+	} {
+		c, err := tcase.a.Compare(tcase.b)
+		assert.NoError(err)
+		assert.Equal(tcase.exp, c, "%s vs %s", tcase.a, tcase.b)
+	}
+}
+
+func TestCompareFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := (&Value{Type: Integer, Val: big.NewInt(1)}).Compare(&Value{Type: String, Val: "1"})
+	assert.EqualError(err, "cannot compare values of type *big.Int and string")
+
+	_, err = (&Value{Type: Integer, Val: big.NewInt(1)}).Compare(&Value{Type: Integer, Val: "1"})
+	assert.EqualError(err, "expected type *big.Int, got string")
+
+	_, err = (&Value{Type: UintArray, Val: []uint64{1}}).Compare(&Value{Type: UintArray, Val: []uint64{2}})
+	assert.EqualError(err, "values of type []uint64 are not ordered")
+
+	_, err = (&Value{Type: Float64, Val: math.NaN()}).Compare(&Value{Type: Float64, Val: 1.0})
+	assert.EqualError(err, "cannot compare NaN")
+
+	_, err = (&Value{Type: Integer, Val: big.NewInt(1)}).Compare(nil)
+	assert.Equal(ErrNilValue, err)
+}
@@ -0,0 +1,27 @@
+package abi
+
+import (
+	"math/big"
+
+	"github.com/filecoin-project/go-filecoin/address"
+)
+
+// NewAddress returns an Address value holding addr.
+func NewAddress(addr address.Address) *Value {
+	return &Value{Type: Address, Val: addr}
+}
+
+// NewInteger returns an Integer value holding i.
+func NewInteger(i *big.Int) *Value {
+	return &Value{Type: Integer, Val: i}
+}
+
+// NewBytes returns a Bytes value holding b.
+func NewBytes(b []byte) *Value {
+	return &Value{Type: Bytes, Val: b}
+}
+
+// NewString returns a String value holding s.
+func NewString(s string) *Value {
+	return &Value{Type: String, Val: s}
+}
@@ -0,0 +1,30 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstructorsRoundTrip(t *testing.T) {
+	for _, v := range []*Value{
+		NewAddress(address.NewForTestGetter()()),
+		NewInteger(big.NewInt(-42)),
+		NewBytes([]byte("beep")),
+		NewString("flugzeug"),
+	} {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		data, err := v.Serialize()
+		require.NoError(err)
+
+		out, err := Deserialize(data, v.Type)
+		require.NoError(err)
+		assert.True(v.Equals(out), "expected %s, got %s", v, out)
+	}
+}
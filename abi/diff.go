@@ -0,0 +1,47 @@
+package abi
+
+import (
+	"fmt"
+)
+
+// DiffValues returns a human readable description of every difference between
+// two lists of values, one line per difference. It returns nil if the lists
+// are equal.
+func DiffValues(a, b []*Value) []string {
+	var diffs []string
+	if len(a) != len(b) {
+		diffs = append(diffs, fmt.Sprintf("length mismatch: %d vs %d", len(a), len(b)))
+	}
+
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(b):
+			diffs = append(diffs, fmt.Sprintf("index %d: only in a: %s", i, a[i]))
+		case i >= len(a):
+			diffs = append(diffs, fmt.Sprintf("index %d: only in b: %s", i, b[i]))
+		case a[i] == nil || b[i] == nil:
+			if a[i] != b[i] {
+				diffs = append(diffs, fmt.Sprintf("index %d: value mismatch: %s vs %s", i, a[i], b[i]))
+			}
+		case a[i].Type != b[i].Type:
+			diffs = append(diffs, fmt.Sprintf("index %d: type mismatch: %s vs %s", i, a[i].Type, b[i].Type))
+		case !a[i].Equals(b[i]):
+			diffs = append(diffs, fmt.Sprintf("index %d: value mismatch: %s vs %s", i, a[i], b[i]))
+		}
+	}
+	return diffs
+}
+
+// ValuesEqual reports whether two lists of values have the same length and
+// are pairwise equal according to Equals.
+func ValuesEqual(a, b []*Value) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
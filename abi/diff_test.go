@@ -0,0 +1,64 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffValues(t *testing.T) {
+	assert := assert.New(t)
+
+	a := []*Value{
+		{Type: Integer, Val: big.NewInt(1)},
+		{Type: String, Val: "foo"},
+		{Type: Bytes, Val: []byte{0xbe, 0xef}},
+	}
+	assert.Nil(DiffValues(a, a))
+	assert.Nil(DiffValues(nil, nil))
+
+	b := []*Value{
+		{Type: Integer, Val: big.NewInt(1)},
+		{Type: Bytes, Val: []byte("foo")},
+		{Type: Bytes, Val: []byte{0xbe, 0xee}},
+		{Type: Boolean, Val: true},
+	}
+
+	assert.Equal([]string{
+		"length mismatch: 3 vs 4",
+		"index 1: type mismatch: string vs []byte",
+		"index 2: value mismatch: []byte(beef) vs []byte(beee)",
+		"index 3: only in b: bool(true)",
+	}, DiffValues(a, b))
+
+	assert.Equal([]string{
+		"length mismatch: 1 vs 0",
+		"index 0: only in a: *big.Int(1)",
+	}, DiffValues(a[:1], nil))
+
+	assert.Equal([]string{
+		"index 0: value mismatch: <nil> vs *big.Int(1)",
+	}, DiffValues([]*Value{nil}, a[:1]))
+}
+
+func TestValuesEqual(t *testing.T) {
+	assert := assert.New(t)
+
+	n, _ := new(big.Int).SetString("12345678901234567890", 10)
+	m := new(big.Int).Mul(big.NewInt(1234567890), big.NewInt(10000000000))
+	m.Add(m, big.NewInt(1234567890))
+
+	a := []*Value{NewInteger(n), NewBytes([]byte("beep")), NewString("foo")}
+	b := []*Value{NewInteger(m), NewBytes(append([]byte{}, "beep"...)), NewString("foo")}
+	assert.True(ValuesEqual(a, b))
+	assert.True(ValuesEqual(nil, []*Value{}))
+
+	assert.False(ValuesEqual(a, b[:2]))
+
+	b[1] = NewBytes([]byte("beer"))
+	assert.False(ValuesEqual(a, b))
+
+	b[1] = nil
+	assert.False(ValuesEqual(a, b))
+}
@@ -1,7 +1,10 @@
 package abi
 
 import (
+	"encoding/binary"
 	"fmt"
+	"hash"
+	"math"
 
 	cbor "gx/ipfs/QmRoARq3nkUb13HSKZGepCZSWe5GrVPwx7xURJGZ7KWv9V/go-ipld-cbor"
 	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
@@ -46,7 +49,7 @@ func DecodeValues(data []byte, types []Type) ([]*Value, error) {
 
 	out := make([]*Value, 0, len(types))
 	for i, t := range types {
-		v, err := Deserialize(arr[i], t)
+		v, err := DeserializeAt(arr[i], t, i)
 		if err != nil {
 			return nil, err
 		}
@@ -55,6 +58,307 @@ func DecodeValues(data []byte, types []Type) ([]*Value, error) {
 	return out, nil
 }
 
+// LengthPrefix selects how segments are framed by SerializeValuesWithPrefix
+// and DeserializeValuesWithPrefix.
+type LengthPrefix int
+
+const (
+	// Varint prefixes each segment with its length as a uvarint.
+	Varint = LengthPrefix(iota)
+	// Fixed32 prefixes each segment with its length as a 4 byte big endian
+	// number, for fixed layout structures.
+	Fixed32
+)
+
+// SerializeValues encodes a set of abi values into a single blob of segments,
+// each prefixed with its length as a uvarint. Zero length arrays of values are
+// normalized to nil
+func SerializeValues(vals []*Value) ([]byte, error) {
+	return SerializeValuesWithPrefix(vals, Varint)
+}
+
+// SerializeValuesWithPrefix works like SerializeValues, but frames the
+// segments with the given kind of length prefix.
+func SerializeValuesWithPrefix(vals []*Value, prefix LengthPrefix) ([]byte, error) {
+	if prefix != Varint && prefix != Fixed32 {
+		return nil, fmt.Errorf("unknown length prefix: %d", prefix)
+	}
+	if len(vals) == 0 {
+		return nil, nil
+	}
+
+	var out []byte
+	for _, val := range vals {
+		data, err := val.Serialize()
+		if err != nil {
+			return nil, err
+		}
+
+		if prefix == Fixed32 {
+			if uint64(len(data)) > math.MaxUint32 {
+				return nil, fmt.Errorf("segment of %d bytes is too long for a fixed32 length prefix", len(data))
+			}
+			out = appendFixed32Segment(out, data)
+		} else {
+			out = appendSegment(out, data)
+		}
+	}
+
+	return out, nil
+}
+
+// HashValues writes the serialized values to h, each prefixed with its length
+// as a uvarint. This is the same framing SerializeValues uses. Types are not
+// hashed, so values of different types with the same encoding collide.
+func HashValues(vals []*Value, h hash.Hash) error {
+	var buf []byte
+	for i, val := range vals {
+		data, err := val.Serialize()
+		if err != nil {
+			return errors.Wrapf(err, "parameter %d", i)
+		}
+
+		buf = appendSegment(buf[:0], data)
+		if _, err := h.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrTrailingData is returned by DeserializeValues when data is left over
+// after all parameters have been decoded.
+var ErrTrailingData = fmt.Errorf("trailing data")
+
+// DeserializeValues decodes a blob produced by SerializeValues, using the
+// provided type information. It errors if there are fewer segments than types,
+// and returns an error wrapping ErrTrailingData if any bytes remain after the
+// last one.
+func DeserializeValues(data []byte, types []Type) ([]*Value, error) {
+	return DeserializeValuesWithPrefix(data, types, Varint)
+}
+
+// DeserializeValuesWithPrefix works like DeserializeValues, for blobs produced
+// by SerializeValuesWithPrefix with the same kind of length prefix.
+func DeserializeValuesWithPrefix(data []byte, types []Type, prefix LengthPrefix) ([]*Value, error) {
+	out, rest, err := deserializeValues(data, types, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) != 0 {
+		return nil, errors.Wrapf(ErrTrailingData, "%d bytes left after %d parameters", len(rest), len(types))
+	}
+	return out, nil
+}
+
+// DeserializeValuesLenient works like DeserializeValues, but ignores any data
+// following the last parameter.
+func DeserializeValuesLenient(data []byte, types []Type) ([]*Value, error) {
+	out, _, err := deserializeValues(data, types, Varint)
+	return out, err
+}
+
+// DeserializePartial works like DeserializeValues, but instead of failing as a
+// whole it returns the values decoded before the first failure, their number,
+// and an error describing where decoding stopped. Every returned value passes
+// Validate. It is meant for salvaging what is left of corrupt data.
+func DeserializePartial(data []byte, types []Type) ([]*Value, int, error) {
+	var out []*Value
+	for i, t := range types {
+		if len(data) == 0 {
+			return out, i, fmt.Errorf("expected %d parameters, but got %d", len(types), i)
+		}
+
+		segment, rest, err := readSegment(data, i)
+		if err != nil {
+			return out, i, err
+		}
+
+		v, err := DeserializeAt(segment, t, i)
+		if err != nil {
+			return out, i, err
+		}
+		if err := v.Validate(); err != nil {
+			return out, i, errors.Wrapf(err, "parameter %d", i)
+		}
+
+		out = append(out, v)
+		data = rest
+	}
+
+	if len(data) != 0 {
+		return out, len(out), errors.Wrapf(ErrTrailingData, "%d bytes left after %d parameters", len(data), len(types))
+	}
+	return out, len(out), nil
+}
+
+// EncodingVersion is the version of the wire format written by
+// SerializeVersioned. It is the only version DeserializeVersioned accepts.
+const EncodingVersion uint8 = 1
+
+// SerializeVersioned works like SerializeValues, but prefixes the output with
+// a version byte, so that the format can be changed later on.
+func SerializeVersioned(version uint8, vals []*Value) ([]byte, error) {
+	if version != EncodingVersion {
+		return nil, fmt.Errorf("unsupported encoding version %d", version)
+	}
+
+	data, err := SerializeValues(vals)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{version}, data...), nil
+}
+
+// DeserializeVersioned decodes a blob produced by SerializeVersioned, using
+// the provided type information. It errors on versions it doesn't know.
+func DeserializeVersioned(data []byte, types []Type) (uint8, []*Value, error) {
+	if len(data) == 0 {
+		return 0, nil, fmt.Errorf("missing encoding version")
+	}
+
+	version := data[0]
+	if version != EncodingVersion {
+		return 0, nil, fmt.Errorf("unsupported encoding version %d", version)
+	}
+
+	vals, err := DeserializeValues(data[1:], types)
+	if err != nil {
+		return 0, nil, err
+	}
+	return version, vals, nil
+}
+
+func deserializeValues(data []byte, types []Type, prefix LengthPrefix) ([]*Value, []byte, error) {
+	if prefix != Varint && prefix != Fixed32 {
+		return nil, nil, fmt.Errorf("unknown length prefix: %d", prefix)
+	}
+	if len(types) == 0 {
+		return nil, data, nil
+	}
+
+	out := make([]*Value, 0, len(types))
+	for i, t := range types {
+		if len(data) == 0 {
+			return nil, nil, fmt.Errorf("expected %d parameters, but got %d", len(types), i)
+		}
+
+		var segment []byte
+		var err error
+		if prefix == Fixed32 {
+			segment, data, err = readFixed32Segment(data, i)
+		} else {
+			segment, data, err = readSegment(data, i)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		v, err := DeserializeAt(segment, t, i)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, v)
+	}
+	return out, data, nil
+}
+
+func appendSegment(buf []byte, data []byte) []byte {
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(data)))
+	buf = append(buf, lenBuf[:n]...)
+	return append(buf, data...)
+}
+
+func readSegments(data []byte) ([][]byte, error) {
+	var segments [][]byte
+	for len(data) > 0 {
+		var segment []byte
+		var err error
+		segment, data, err = readSegment(data, len(segments))
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// readSegment reads the segment with the given index from the start of data
+// and returns it together with the remaining data.
+func readSegment(data []byte, index int) ([]byte, []byte, error) {
+	n, k := binary.Uvarint(data)
+	if k <= 0 {
+		return nil, nil, fmt.Errorf("invalid length prefix for segment %d", index)
+	}
+	data = data[k:]
+
+	if uint64(len(data)) < n {
+		return nil, nil, fmt.Errorf("segment %d is truncated: expected %d bytes, got %d", index, n, len(data))
+	}
+
+	return data[:n], data[n:], nil
+}
+
+// SegmentReader walks the segments of a blob produced by SerializeValues
+// without decoding them.
+type SegmentReader struct {
+	data  []byte
+	index int
+	err   error
+}
+
+// NewSegmentReader returns a SegmentReader over data.
+func NewSegmentReader(data []byte) *SegmentReader {
+	return &SegmentReader{data: data}
+}
+
+// Next returns the next raw segment. It returns false once all segments have
+// been read or a malformed segment is encountered, see Err.
+func (sr *SegmentReader) Next() ([]byte, bool) {
+	if sr.err != nil || len(sr.data) == 0 {
+		return nil, false
+	}
+
+	segment, rest, err := readSegment(sr.data, sr.index)
+	if err != nil {
+		sr.err = err
+		return nil, false
+	}
+
+	sr.data = rest
+	sr.index++
+	return segment, true
+}
+
+// Err returns the error that stopped Next, if any.
+func (sr *SegmentReader) Err() error {
+	return sr.err
+}
+
+func appendFixed32Segment(buf []byte, data []byte) []byte {
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(data)))
+	buf = append(buf, lenBuf[:]...)
+	return append(buf, data...)
+}
+
+func readFixed32Segment(data []byte, index int) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, fmt.Errorf("invalid fixed32 length prefix for segment %d", index)
+	}
+	n := binary.BigEndian.Uint32(data)
+	data = data[4:]
+
+	if uint64(len(data)) < uint64(n) {
+		return nil, nil, fmt.Errorf("segment %d is truncated: fixed32 length prefix expects %d bytes, got %d", index, n, len(data))
+	}
+
+	return data[:n], data[n:], nil
+}
+
 // ToEncodedValues converts from a list of go abi-compatible values to abi values and then encodes to raw bytes.
 func ToEncodedValues(params ...interface{}) ([]byte, error) {
 	vals, err := ToValues(params)
@@ -69,3 +373,24 @@ func ToEncodedValues(params ...interface{}) ([]byte, error) {
 
 	return bytes, nil
 }
+
+// Pack converts the given go values to abi values and serializes them into a
+// single blob, see SerializeValues.
+func Pack(args ...interface{}) ([]byte, error) {
+	vals, err := ToValues(args)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to convert params to values")
+	}
+
+	return SerializeValues(vals)
+}
+
+// Unpack reverses Pack, decoding the blob into go values of the given types.
+func Unpack(data []byte, types ...Type) ([]interface{}, error) {
+	vals, err := DeserializeValues(data, types)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromValues(vals), nil
+}
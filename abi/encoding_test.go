@@ -1,10 +1,14 @@
 package abi
 
 import (
+	"crypto/sha256"
 	"math/big"
 	"testing"
 
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+
 	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,6 +29,7 @@ func TestBasicEncodingRoundTrip(t *testing.T) {
 		"a string":   {"flugzeug"},
 		"mixed":      {big.NewInt(17), []byte("beep"), "mr rogers", addrGetter()},
 		"sector ids": {uint64(1234), uint64(0)},
+		"bools":      {true, false},
 	}
 
 	for tname, tcase := range cases {
@@ -76,6 +81,16 @@ func TestToValuesFailures(t *testing.T) {
 			vals:   []interface{}{&fooTestStruct{"b", 99}},
 			expErr: "unsupported type: *abi.fooTestStruct",
 		},
+		{
+			name:   "nil integer",
+			vals:   []interface{}{"foo", (*big.Int)(nil), "bar"},
+			expErr: "parameter 1: cannot use nil *big.Int",
+		},
+		{
+			name:   "nil token amount",
+			vals:   []interface{}{(*types.AttoFIL)(nil)},
+			expErr: "parameter 0: cannot use nil *types.AttoFIL",
+		},
 	}
 
 	for _, tcase := range cases {
@@ -86,3 +101,389 @@ func TestToValuesFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializeValuesRoundTrip(t *testing.T) {
+	addrGetter := address.NewForTestGetter()
+
+	cases := map[string][]interface{}{
+		"empty":   nil,
+		"one-int": {big.NewInt(-579)},
+		"mixed":   {big.NewInt(17), []byte("beep"), "", addrGetter(), uint64(3), true, []byte{}},
+	}
+
+	for tname, tcase := range cases {
+		t.Run(tname, func(t *testing.T) {
+			assert := assert.New(t)
+			vals, err := ToValues(tcase)
+			assert.NoError(err)
+
+			data, err := SerializeValues(vals)
+			assert.NoError(err)
+
+			var types []Type
+			for _, val := range vals {
+				types = append(types, val.Type)
+			}
+
+			outVals, err := DeserializeValues(data, types)
+			assert.NoError(err)
+			assert.Equal(len(vals), len(outVals))
+			for i := range vals {
+				assert.True(vals[i].Equals(outVals[i]), "expected %s, got %s", vals[i], outVals[i])
+			}
+		})
+	}
+}
+
+func TestSerializeValuesEmpty(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := SerializeValues([]*Value{})
+	assert.NoError(err)
+	assert.Nil(data)
+
+	vals, err := DeserializeValues(data, []Type{})
+	assert.NoError(err)
+	assert.Nil(vals)
+}
+
+func TestDeserializeValuesFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := SerializeValues([]*Value{{Type: String, Val: "foo"}, {Type: String, Val: "bar"}})
+	assert.NoError(err)
+
+	_, err = DeserializeValues(data, []Type{String})
+	assert.EqualError(err, "4 bytes left after 1 parameters: trailing data")
+
+	_, err = DeserializeValues(data, []Type{String, String, String})
+	assert.EqualError(err, "expected 3 parameters, but got 2")
+
+	_, err = DeserializeValues(nil, []Type{String})
+	assert.EqualError(err, "expected 1 parameters, but got 0")
+
+	_, err = DeserializeValues(data[:len(data)-1], []Type{String, String})
+	assert.EqualError(err, "segment 1 is truncated: expected 3 bytes, got 2")
+
+	_, err = DeserializeValues([]byte{0x80}, []Type{String})
+	assert.EqualError(err, "invalid length prefix for segment 0")
+}
+
+func TestDeserializeAt(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := DeserializeAt([]byte{0x01}, Boolean, 3)
+	assert.NoError(err)
+
+	_, err = DeserializeAt([]byte{0x01, 0x02}, Boolean, 3)
+	assert.EqualError(err, "parameter 3: failed to decode bool: Boolean: expected 1 byte, got 2")
+
+	data, err := SerializeValues([]*Value{{Type: String, Val: "foo"}, {Type: String, Val: "bar"}})
+	assert.NoError(err)
+
+	_, err = DeserializeValues(data, []Type{String, Type(99)})
+	assert.EqualError(err, "parameter 1: failed to decode <unknown type>: unrecognized Type: 99")
+}
+
+func TestEncodeNilValue(t *testing.T) {
+	assert := assert.New(t)
+
+	vals := []*Value{{Type: String, Val: "foo"}, nil}
+
+	_, err := EncodeValues(vals)
+	assert.Equal(ErrNilValue, err)
+
+	_, err = SerializeValues(vals)
+	assert.Equal(ErrNilValue, err)
+}
+
+func TestToValuesUnsupportedTypeError(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ToValues([]interface{}{"foo", big.NewInt(1), 17, "bar"})
+	assert.EqualError(err, "unsupported type: int")
+
+	ute, ok := err.(*UnsupportedTypeError)
+	assert.True(ok)
+	assert.Equal(2, ute.Index)
+	assert.Equal(17, ute.Value)
+}
+
+func TestToValuesNilBlockHeight(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ToValues([]interface{}{(*types.BlockHeight)(nil)})
+	assert.EqualError(err, "parameter 0: cannot use nil *types.BlockHeight")
+}
+
+func TestPackUnpack(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := address.NewForTestGetter()()
+
+	data, err := Pack(addr, big.NewInt(-42), "foo")
+	assert.NoError(err)
+
+	out, err := Unpack(data, Address, Integer, String)
+	assert.NoError(err)
+	assert.Equal([]interface{}{addr, big.NewInt(-42), "foo"}, out)
+
+	_, err = Unpack(data, Address, Integer)
+	assert.EqualError(err, "4 bytes left after 2 parameters: trailing data")
+
+	_, err = Pack(17)
+	assert.EqualError(err, "unable to convert params to values: unsupported type: int")
+
+	data, err = Pack()
+	assert.NoError(err)
+	out, err = Unpack(data)
+	assert.NoError(err)
+	assert.Nil(out)
+}
+
+func TestDeserializeValuesTrailingData(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := SerializeValues([]*Value{{Type: String, Val: "foo"}, {Type: Boolean, Val: true}})
+	assert.NoError(err)
+
+	vals, err := DeserializeValues(data, []Type{String, Boolean})
+	assert.NoError(err)
+	assert.Len(vals, 2)
+
+	_, err = DeserializeValues(append(data, 0xde, 0xad), []Type{String, Boolean})
+	assert.Equal(ErrTrailingData, errors.Cause(err))
+	assert.EqualError(err, "2 bytes left after 2 parameters: trailing data")
+
+	_, err = DeserializeValues(data, nil)
+	assert.EqualError(err, "6 bytes left after 0 parameters: trailing data")
+
+	vals, err = DeserializeValuesLenient(append(data, 0xde, 0xad), []Type{String, Boolean})
+	assert.NoError(err)
+	assert.Len(vals, 2)
+}
+
+func TestFromValuesCopy(t *testing.T) {
+	assert := assert.New(t)
+
+	vals := []*Value{
+		{Type: Integer, Val: big.NewInt(100)},
+		{Type: Bytes, Val: []byte{1, 2, 3}},
+		{Type: String, Val: "foo"},
+	}
+
+	out := FromValuesCopy(vals)
+	assert.Equal(FromValues(vals), out)
+
+	out[0].(*big.Int).Add(out[0].(*big.Int), big.NewInt(1))
+	out[1].([]byte)[0] = 0xff
+	assert.Equal(big.NewInt(100), vals[0].Val)
+	assert.Equal([]byte{1, 2, 3}, vals[1].Val)
+
+	// FromValues shares the underlying values
+	FromValues(vals)[0].(*big.Int).SetInt64(7)
+	assert.Equal(big.NewInt(7), vals[0].Val)
+
+	assert.Nil(FromValuesCopy(nil))
+}
+
+func TestToValuesRestricted(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := address.NewForTestGetter()()
+	allowed := []Type{Address, Integer}
+
+	vals, err := ToValuesRestricted([]interface{}{addr, big.NewInt(1)}, allowed)
+	assert.NoError(err)
+	assert.Len(vals, 2)
+
+	_, err = ToValuesRestricted([]interface{}{addr, "foo"}, allowed)
+	assert.EqualError(err, "parameter 1: type string is not allowed")
+
+	_, err = ToValuesRestricted([]interface{}{17}, allowed)
+	assert.EqualError(err, "unsupported type: int")
+
+	vals, err = ToValuesRestricted(nil, nil)
+	assert.NoError(err)
+	assert.Nil(vals)
+}
+
+func TestSerializeVersioned(t *testing.T) {
+	assert := assert.New(t)
+
+	vals, err := ToValues([]interface{}{big.NewInt(17), "flugzeug"})
+	assert.NoError(err)
+	types := []Type{Integer, String}
+
+	data, err := SerializeVersioned(EncodingVersion, vals)
+	assert.NoError(err)
+	assert.Equal(EncodingVersion, data[0])
+
+	version, out, err := DeserializeVersioned(data, types)
+	assert.NoError(err)
+	assert.Equal(EncodingVersion, version)
+	assert.Len(out, 2)
+	for i := range vals {
+		assert.True(vals[i].Equals(out[i]), "expected %s, got %s", vals[i], out[i])
+	}
+
+	// a node from the future
+	data[0] = EncodingVersion + 1
+	_, _, err = DeserializeVersioned(data, types)
+	assert.EqualError(err, "unsupported encoding version 2")
+
+	_, err = SerializeVersioned(EncodingVersion+1, vals)
+	assert.EqualError(err, "unsupported encoding version 2")
+
+	_, _, err = DeserializeVersioned(nil, types)
+	assert.EqualError(err, "missing encoding version")
+}
+
+func TestHashValues(t *testing.T) {
+	assert := assert.New(t)
+
+	sum := func(vals ...interface{}) []byte {
+		avals, err := ToValues(vals)
+		assert.NoError(err)
+
+		h := sha256.New()
+		assert.NoError(HashValues(avals, h))
+		return h.Sum(nil)
+	}
+
+	assert.Equal(sum(big.NewInt(17), []byte("beep")), sum(big.NewInt(17), []byte("beep")))
+	assert.NotEqual(sum(big.NewInt(17), []byte("beep")), sum(big.NewInt(17), []byte("beer")))
+
+	// the length delimiter keeps segment boundaries apart
+	assert.NotEqual(sum([]byte("ab"), []byte("c")), sum([]byte("a"), []byte("bc")))
+
+	err := HashValues([]*Value{{Type: String, Val: "foo"}, nil}, sha256.New())
+	assert.EqualError(err, "parameter 1: "+ErrNilValue.Error())
+}
+
+func TestSegmentReader(t *testing.T) {
+	assert := assert.New(t)
+
+	vals, err := ToValues([]interface{}{"foo", []byte{}, big.NewInt(-3)})
+	assert.NoError(err)
+	data, err := SerializeValues(vals)
+	assert.NoError(err)
+
+	sr := NewSegmentReader(data)
+	var segments [][]byte
+	for {
+		segment, ok := sr.Next()
+		if !ok {
+			break
+		}
+		segments = append(segments, segment)
+	}
+	assert.NoError(sr.Err())
+	assert.Equal([][]byte{[]byte("foo"), {}, {0x01, 0x03}}, segments)
+
+	// a length prefix claiming more bytes than there are
+	sr = NewSegmentReader(append(data, 0x05, 'a'))
+	for i := 0; i < 3; i++ {
+		_, ok := sr.Next()
+		assert.True(ok)
+	}
+	_, ok := sr.Next()
+	assert.False(ok)
+	assert.EqualError(sr.Err(), "segment 3 is truncated: expected 5 bytes, got 1")
+
+	sr = NewSegmentReader([]byte{0x80})
+	_, ok = sr.Next()
+	assert.False(ok)
+	assert.EqualError(sr.Err(), "invalid length prefix for segment 0")
+}
+
+func TestSerializeValuesWithPrefix(t *testing.T) {
+	vals, err := ToValues([]interface{}{"foo", big.NewInt(-300), []byte{}})
+	assert.NoError(t, err)
+	types := []Type{String, Integer, Bytes}
+
+	for _, prefix := range []LengthPrefix{Varint, Fixed32} {
+		assert := assert.New(t)
+
+		data, err := SerializeValuesWithPrefix(vals, prefix)
+		assert.NoError(err)
+
+		out, err := DeserializeValuesWithPrefix(data, types, prefix)
+		assert.NoError(err)
+		assert.Len(out, len(vals))
+		for i := range vals {
+			assert.True(vals[i].Equals(out[i]), "expected %s, got %s", vals[i], out[i])
+		}
+	}
+
+	fixed, err := SerializeValuesWithPrefix(vals, Fixed32)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 3, 'f', 'o', 'o'}, fixed[:7])
+
+	varint, err := SerializeValues(vals)
+	assert.NoError(t, err)
+	assert.NotEqual(t, varint, fixed)
+
+	_, err = SerializeValuesWithPrefix(vals, LengthPrefix(7))
+	assert.EqualError(t, err, "unknown length prefix: 7")
+}
+
+func TestDeserializeValuesWithPrefixMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	vals, err := ToValues([]interface{}{"foo", "bar"})
+	assert.NoError(err)
+	types := []Type{String, String}
+
+	varint, err := SerializeValuesWithPrefix(vals, Varint)
+	assert.NoError(err)
+	_, err = DeserializeValuesWithPrefix(varint, types, Fixed32)
+	assert.EqualError(err, "segment 0 is truncated: fixed32 length prefix expects 57044847 bytes, got 4")
+
+	fixed, err := SerializeValuesWithPrefix(vals, Fixed32)
+	assert.NoError(err)
+	_, err = DeserializeValuesWithPrefix(fixed, types, Varint)
+	assert.Error(err)
+
+	_, err = DeserializeValuesWithPrefix([]byte{0, 0}, []Type{String}, Fixed32)
+	assert.EqualError(err, "invalid fixed32 length prefix for segment 0")
+
+	_, err = DeserializeValuesWithPrefix(varint, types, LengthPrefix(7))
+	assert.EqualError(err, "unknown length prefix: 7")
+}
+
+func TestDeserializePartial(t *testing.T) {
+	assert := assert.New(t)
+
+	vals, err := ToValues([]interface{}{"foo", big.NewInt(-7), []byte("beep")})
+	assert.NoError(err)
+	types := []Type{String, Integer, Bytes}
+
+	data, err := SerializeValues(vals)
+	assert.NoError(err)
+
+	// cut into the third segment
+	out, n, err := DeserializePartial(data[:len(data)-2], types)
+	assert.EqualError(err, "segment 2 is truncated: expected 4 bytes, got 2")
+	assert.Equal(2, n)
+	assert.Len(out, 2)
+	for i := range out {
+		assert.True(vals[i].Equals(out[i]), "expected %s, got %s", vals[i], out[i])
+		assert.NoError(out[i].Validate())
+	}
+
+	// a value that fails to decode stops at that value
+	out, n, err = DeserializePartial(data, []Type{String, Address, Bytes})
+	assert.Error(err)
+	assert.Equal(1, n)
+	assert.Len(out, 1)
+
+	out, n, err = DeserializePartial(data, types)
+	assert.NoError(err)
+	assert.Equal(3, n)
+	assert.Len(out, 3)
+
+	_, n, err = DeserializePartial(data, types[:2])
+	assert.Equal(ErrTrailingData, errors.Cause(err))
+	assert.Equal(2, n)
+}
@@ -0,0 +1,75 @@
+package abi
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fuzzSeeds returns inputs that commonly trip up decoders. They double as the
+// seed corpus for go-fuzz.
+func fuzzSeeds(t *testing.T) [][]byte {
+	rng := rand.New(rand.NewSource(42))
+	random := make([]byte, 1<<20)
+	rng.Read(random)
+
+	seeds := [][]byte{
+		nil,
+		{},
+		bytes.Repeat([]byte{0x00}, 1<<20),
+		bytes.Repeat([]byte{0xff}, 1<<20),
+		random,
+	}
+	for b := 0; b < 256; b++ {
+		seeds = append(seeds, []byte{byte(b)}, []byte{byte(b), 0xff, 0xff, 0xff}, bytes.Repeat([]byte{byte(b)}, 33))
+	}
+	for _, v := range canonicalValues(t) {
+		data, err := v.Serialize()
+		assert.NoError(t, err)
+		for i := range data {
+			seeds = append(seeds, data[:i])
+		}
+	}
+	return seeds
+}
+
+func TestDeserializeNeverPanics(t *testing.T) {
+	seeds := fuzzSeeds(t)
+
+	for _, typ := range append(SupportedTypes(), Invalid, Type(9999)) {
+		t.Run(typ.String(), func(t *testing.T) {
+			for _, data := range seeds {
+				assert.NotPanics(t, func() {
+					DeserializeSafe(data, typ) // nolint: errcheck
+				}, "input %x", data)
+			}
+		})
+	}
+}
+
+type panickingCodec struct{}
+
+func (panickingCodec) Encode(interface{}) ([]byte, error) { panic("encode") }
+
+func (panickingCodec) Decode([]byte) (interface{}, error) { panic("decode") }
+
+func TestDeserializeSafe(t *testing.T) {
+	assert := assert.New(t)
+
+	v, err := DeserializeSafe([]byte{0x01}, Boolean)
+	assert.NoError(err)
+	assert.Equal(true, v.Val)
+
+	_, err = DeserializeSafe([]byte{0x02}, Boolean)
+	assert.EqualError(err, "invalid boolean encoding: 0x2")
+
+	typ := Type(1003)
+	assert.NoError(RegisterType(typ, panickingCodec{}))
+	defer unregisterType(typ)
+
+	v, err = DeserializeSafe([]byte{0x01}, typ)
+	assert.Nil(v)
+	assert.EqualError(err, "panic decoding <unknown type>: decode")
+}
@@ -0,0 +1,35 @@
+package abi
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// GobEncode implements the gob.GobEncoder interface. The value is encoded as
+// its type as a uvarint followed by the serialized value, so that the go type
+// of Val is restored on decoding.
+func (av *Value) GobEncode() ([]byte, error) {
+	if av == nil {
+		return nil, ErrNilValue
+	}
+
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(av.Type))
+	return av.SerializeAppend(buf[:n])
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (av *Value) GobDecode(data []byte) error {
+	t, n := binary.Uvarint(data)
+	if n <= 0 {
+		return fmt.Errorf("invalid gob encoding: bad type prefix")
+	}
+
+	v, err := Deserialize(data[n:], Type(t))
+	if err != nil {
+		return err
+	}
+
+	*av = *v
+	return nil
+}
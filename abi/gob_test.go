@@ -0,0 +1,61 @@
+package abi
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGobRoundTrip(t *testing.T) {
+	for name, v := range canonicalValues(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var buf bytes.Buffer
+			require.NoError(gob.NewEncoder(&buf).Encode(v))
+
+			var out Value
+			require.NoError(gob.NewDecoder(&buf).Decode(&out))
+			assert.True(v.Equals(&out), "expected %s, got %s", v, &out)
+		})
+	}
+}
+
+func TestGobBigIntInStruct(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	type cached struct {
+		Params []*Value
+	}
+
+	huge, ok := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	require.True(ok)
+	in := cached{Params: []*Value{{Type: Integer, Val: huge}, {Type: String, Val: "foo"}}}
+
+	var buf bytes.Buffer
+	require.NoError(gob.NewEncoder(&buf).Encode(in))
+
+	var out cached
+	require.NoError(gob.NewDecoder(&buf).Decode(&out))
+	require.Len(out.Params, 2)
+	assert.Equal(0, huge.Cmp(out.Params[0].Val.(*big.Int)))
+	assert.Equal("foo", out.Params[1].Val)
+}
+
+func TestGobDecodeFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	var v Value
+	assert.EqualError(v.GobDecode(nil), "invalid gob encoding: bad type prefix")
+	assert.EqualError(v.GobDecode([]byte{byte(Boolean), 0x02}), "invalid boolean encoding: 0x2")
+	assert.Equal(ErrInvalidType, v.GobDecode([]byte{byte(Invalid)}))
+
+	_, err := (*Value)(nil).GobEncode()
+	assert.Equal(ErrNilValue, err)
+}
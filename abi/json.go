@@ -0,0 +1,177 @@
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+	"gx/ipfs/QmY5Grm8pJdiSSVsYxx4uNRgweY72EmYwuSDbRnbFok3iY/go-libp2p-peer"
+)
+
+// jsonValue is the json representation of a Value.
+type jsonValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is encoded as
+// an object holding the name of its type and the value itself. Integers are
+// encoded as decimal strings in order to not lose precision. Custom types are
+// encoded as the base64 of their codec's output.
+func (av *Value) MarshalJSON() ([]byte, error) {
+	if av == nil {
+		return []byte("null"), nil
+	}
+
+	if av.Type == Invalid {
+		return nil, ErrInvalidType
+	}
+	rt, ok := typeTable[av.Type]
+	if !ok {
+		return marshalCustomJSON(av)
+	}
+	if reflect.TypeOf(av.Val) != rt {
+		return nil, &typeError{reflect.Zero(rt).Interface(), av.Val}
+	}
+
+	val := av.Val
+	switch av.Type {
+	case Integer, Int256:
+		val = av.Val.(*big.Int).String()
+	case SectorID, Uint64:
+		val = strconv.FormatUint(av.Val.(uint64), 10)
+	case Int64:
+		val = strconv.FormatInt(av.Val.(int64), 10)
+	case PeerID:
+		val = peer.IDB58Encode(av.Val.(peer.ID))
+	}
+
+	raw, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonValue{Type: av.Type.String(), Value: raw})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (av *Value) UnmarshalJSON(data []byte) error {
+	var jv jsonValue
+	if err := json.Unmarshal(data, &jv); err != nil {
+		return err
+	}
+
+	t, err := TypeFromString(jv.Type)
+	if err != nil {
+		return err
+	}
+	if _, ok := typeTable[t]; !ok {
+		return av.unmarshalCustomJSON(t, jv.Value)
+	}
+
+	var val interface{}
+	switch t {
+	case Integer, Int256:
+		var s string
+		if err := json.Unmarshal(jv.Value, &s); err != nil {
+			return err
+		}
+
+		intgr, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return fmt.Errorf("invalid integer: %q", s)
+		}
+		if t == Int256 {
+			if err := checkInt256(intgr); err != nil {
+				return err
+			}
+		}
+		val = intgr
+	case SectorID, Uint64:
+		var s string
+		if err := json.Unmarshal(jv.Value, &s); err != nil {
+			return err
+		}
+
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "invalid uint64")
+		}
+		val = n
+	case Int64:
+		var s string
+		if err := json.Unmarshal(jv.Value, &s); err != nil {
+			return err
+		}
+
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "invalid int64")
+		}
+		val = n
+	case PeerID:
+		var s string
+		if err := json.Unmarshal(jv.Value, &s); err != nil {
+			return err
+		}
+
+		id, err := peer.IDB58Decode(s)
+		if err != nil {
+			return errors.Wrap(err, "invalid peer id")
+		}
+		val = id
+	default:
+		ptr := reflect.New(typeTable[t])
+		if err := json.Unmarshal(jv.Value, ptr.Interface()); err != nil {
+			return err
+		}
+		val = ptr.Elem().Interface()
+	}
+
+	av.Type = t
+	av.Val = val
+	return nil
+}
+
+// Custom types are carried as the bytes produced by their codec. They need a
+// declared name, otherwise the type couldn't be read back.
+func marshalCustomJSON(av *Value) ([]byte, error) {
+	name, ok := declaredName(av.Type)
+	if !ok {
+		return nil, fmt.Errorf("unrecognized Type: %d", av.Type)
+	}
+
+	data, err := av.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonValue{Type: name, Value: raw})
+}
+
+func (av *Value) unmarshalCustomJSON(t Type, raw json.RawMessage) error {
+	if _, err := customCodec(t); err != nil {
+		return err
+	}
+
+	var data []byte
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	v, err := Deserialize(data, t)
+	if err != nil {
+		return err
+	}
+
+	*av = *v
+	return nil
+}
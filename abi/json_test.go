@@ -0,0 +1,128 @@
+package abi
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"gx/ipfs/QmR8BauakNcBa3RbE4nbQu76PDiJgoQgz8AJdhJuiU4TAw/go-cid"
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+	mh "gx/ipfs/QmerPMzPk1mJVowm8KgmoknWa4yCYvvugMPsgWmDNUvDLW/go-multihash"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	addrGetter := address.NewForTestGetter()
+
+	c, err := cid.NewPrefixV1(cid.DagCBOR, mh.SHA2_256).Sum([]byte("piece"))
+	require.NoError(t, err)
+
+	cases := map[string]*Value{
+		"address":      {Type: Address, Val: addrGetter()},
+		"attofil":      {Type: AttoFIL, Val: types.NewAttoFILFromFIL(12)},
+		"block height": {Type: BlockHeight, Val: types.NewBlockHeight(800)},
+		"integer":      {Type: Integer, Val: big.NewInt(-17)},
+		"bytes":        {Type: Bytes, Val: []byte("beep")},
+		"string":       {Type: String, Val: "mr rogers"},
+		"uint array":   {Type: UintArray, Val: []uint64{1, 2, 3}},
+		"sector id":    {Type: SectorID, Val: uint64(1234)},
+		"boolean":      {Type: Boolean, Val: true},
+		"uint64":       {Type: Uint64, Val: uint64(1<<64 - 1)},
+		"int64":        {Type: Int64, Val: int64(-1 << 63)},
+		"cid":          {Type: Cid, Val: c},
+	}
+
+	for tname, tcase := range cases {
+		t.Run(tname, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			data, err := json.Marshal(tcase)
+			require.NoError(err)
+
+			var out Value
+			require.NoError(json.Unmarshal(data, &out))
+			assert.True(tcase.Equals(&out), "expected %s, got %s", tcase, &out)
+		})
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := json.Marshal(&Value{Type: Bytes, Val: []byte("foo")})
+	assert.NoError(err)
+	assert.Equal(`{"type":"[]byte","value":"Zm9v"}`, string(data))
+
+	data, err = json.Marshal(&Value{Type: Integer, Val: big.NewInt(42)})
+	assert.NoError(err)
+	assert.Equal(`{"type":"*big.Int","value":"42"}`, string(data))
+}
+
+func TestJSONLargeIntegerPrecision(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// 2^53 + 1 is not representable as a float64
+	n := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 53), big.NewInt(1))
+
+	data, err := json.Marshal(&Value{Type: Integer, Val: n})
+	require.NoError(err)
+
+	var out Value
+	require.NoError(json.Unmarshal(data, &out))
+	assert.Equal(0, n.Cmp(out.Val.(*big.Int)))
+}
+
+func TestJSONFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	var out Value
+	assert.EqualError(json.Unmarshal([]byte(`{"type":"complex128","value":"1.0"}`), &out), `unknown type: "complex128"`)
+	assert.EqualError(json.Unmarshal([]byte(`{"type":"*big.Int","value":"1.0"}`), &out), `invalid integer: "1.0"`)
+
+	overflow := new(big.Int).Lsh(big.NewInt(1), 256).String()
+	assert.EqualError(json.Unmarshal([]byte(`{"type":"int256","value":"`+overflow+`"}`), &out), "int256 overflow: "+overflow+" does not fit into 256 bits")
+	assert.EqualError(json.Unmarshal([]byte(`{"type":"int256","value":"-1"}`), &out), "int256 cannot be negative: -1")
+
+	_, err := json.Marshal(&Value{})
+	assert.Error(err)
+
+	_, err = json.Marshal(&Value{Type: Integer, Val: "foo"})
+	assert.Error(err)
+}
+
+func TestJSONCustomTypes(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	voucher := Type(1010)
+	require.NoError(DeclareType(voucher, "voucher"))
+	defer undeclareType(voucher)
+
+	// declared, but without a codec
+	var out Value
+	err := json.Unmarshal([]byte(`{"type":"voucher","value":"AQ=="}`), &out)
+	assert.Equal(ErrNoCodec, errors.Cause(err))
+
+	require.NoError(RegisterType(voucher, bitfieldCodec{}))
+	defer unregisterType(voucher)
+
+	in := &Value{Type: voucher, Val: []bool{true, false}}
+	data, err := json.Marshal(in)
+	require.NoError(err)
+	assert.Equal(`{"type":"voucher","value":"AQA="}`, string(data))
+
+	require.NoError(json.Unmarshal(data, &out))
+	assert.Equal(voucher, out.Type)
+	assert.Equal([]bool{true, false}, out.Val)
+
+	// registered, but not declared
+	_, err = (&Value{Type: Type(1011), Val: []bool{true}}).MarshalJSON()
+	assert.EqualError(err, "unrecognized Type: 1011")
+}
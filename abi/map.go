@@ -0,0 +1,67 @@
+package abi
+
+import (
+	"fmt"
+	"sort"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+)
+
+// SerializeMap encodes a map of named values of any types. Like
+// SerializeStruct it writes the entries sorted by key, each as a
+// length-prefixed key followed by the length-prefixed SerializeTagged
+// encoding of the value, so the result is deterministic and needs no schema
+// to decode.
+func SerializeMap(m map[string]*Value) ([]byte, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out []byte
+	for _, k := range keys {
+		data, err := SerializeTagged(m[k])
+		if err != nil {
+			return nil, errors.Wrapf(err, "key %q", k)
+		}
+
+		out = appendSegment(out, []byte(k))
+		out = appendSegment(out, data)
+	}
+
+	return out, nil
+}
+
+// DeserializeMap decodes a map encoded by SerializeMap. It errors on duplicate
+// keys and keys that aren't sorted the way SerializeMap writes them.
+func DeserializeMap(data []byte) (map[string]*Value, error) {
+	segments, err := readSegments(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments)%2 != 0 {
+		return nil, fmt.Errorf("malformed map: key %q has no value", segments[len(segments)-1])
+	}
+
+	out := make(map[string]*Value, len(segments)/2)
+	for i := 0; i < len(segments); i += 2 {
+		k := string(segments[i])
+		if i > 0 {
+			switch prev := string(segments[i-2]); {
+			case k == prev:
+				return nil, fmt.Errorf("duplicate key %q", k)
+			case k < prev:
+				return nil, fmt.Errorf("malformed map: keys out of order")
+			}
+		}
+
+		v, err := DeserializeTagged(segments[i+1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "key %q", k)
+		}
+		out[k] = v
+	}
+
+	return out, nil
+}
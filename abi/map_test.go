@@ -0,0 +1,85 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m := map[string]*Value{
+		"owner":   NewAddress(address.NewForTestGetter()()),
+		"balance": NewInteger(big.NewInt(-42)),
+		"data":    NewBytes([]byte("beep")),
+		"":        NewString(""),
+	}
+
+	data, err := SerializeMap(m)
+	require.NoError(err)
+
+	out, err := DeserializeMap(data)
+	require.NoError(err)
+	require.Len(out, len(m))
+	for k, v := range m {
+		assert.True(v.Equals(out[k]), "key %q: expected %s, got %s", k, v, out[k])
+	}
+}
+
+func TestMapDeterministic(t *testing.T) {
+	assert := assert.New(t)
+
+	// maps built in different orders; go randomizes iteration order anyway
+	a := map[string]*Value{}
+	b := map[string]*Value{}
+	keys := []string{"zeta", "alpha", "mu", "beta"}
+	for i, k := range keys {
+		a[k] = NewInteger(big.NewInt(int64(i)))
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		b[keys[i]] = NewInteger(big.NewInt(int64(i)))
+	}
+
+	dataA, err := SerializeMap(a)
+	assert.NoError(err)
+	dataB, err := SerializeMap(b)
+	assert.NoError(err)
+	assert.Equal(dataA, dataB)
+
+	for i := 0; i < 10; i++ {
+		again, err := SerializeMap(a)
+		assert.NoError(err)
+		assert.Equal(dataA, again)
+	}
+}
+
+func TestMapFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	entry := appendSegment(appendSegment(nil, []byte("k")), []byte{byte(String), 'v'})
+	_, err := DeserializeMap(append(entry, entry...))
+	assert.EqualError(err, `duplicate key "k"`)
+
+	other := appendSegment(appendSegment(nil, []byte("j")), []byte{byte(String), 'w'})
+	_, err = DeserializeMap(append(append([]byte{}, other...), entry...))
+	assert.NoError(err)
+
+	_, err = DeserializeMap(append(append([]byte{}, entry...), other...))
+	assert.EqualError(err, "malformed map: keys out of order")
+
+	_, err = DeserializeMap(appendSegment(nil, []byte("k")))
+	assert.EqualError(err, `malformed map: key "k" has no value`)
+
+	_, err = SerializeMap(map[string]*Value{"k": nil})
+	assert.EqualError(err, `key "k": `+ErrNilValue.Error())
+
+	out, err := DeserializeMap(nil)
+	assert.NoError(err)
+	assert.Empty(out)
+}
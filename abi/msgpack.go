@@ -0,0 +1,324 @@
+package abi
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// msgpack format bytes used by the value encoding, see
+// https://github.com/msgpack/msgpack/blob/master/spec.md
+const (
+	msgpackFixArray = 0x90
+	msgpackFixStr   = 0xa0
+	msgpackFalse    = 0xc2
+	msgpackTrue     = 0xc3
+	msgpackBin8     = 0xc4
+	msgpackBin16    = 0xc5
+	msgpackBin32    = 0xc6
+	msgpackUint8    = 0xcc
+	msgpackUint16   = 0xcd
+	msgpackUint32   = 0xce
+	msgpackUint64   = 0xcf
+	msgpackStr8     = 0xd9
+	msgpackStr16    = 0xda
+	msgpackStr32    = 0xdb
+)
+
+var errMsgpackTruncated = fmt.Errorf("msgpack: unexpected end of input")
+
+// MarshalMsgpack encodes the value as msgpack. Like MarshalCBOR the encoding
+// is a two element array of the type and the payload. Integers are encoded as
+// decimal strings since msgpack ints are limited to 64 bits.
+func (av *Value) MarshalMsgpack() ([]byte, error) {
+	if av == nil {
+		return nil, ErrNilValue
+	}
+
+	buf := []byte{msgpackFixArray | 2}
+	buf = appendMsgpackUint(buf, uint64(av.Type))
+
+	switch av.Type {
+	case Invalid:
+		return nil, ErrInvalidType
+	case Integer:
+		intgr, ok := av.Val.(*big.Int)
+		if !ok {
+			return nil, &typeError{&big.Int{}, av.Val}
+		}
+
+		return appendMsgpackStr(buf, intgr.String()), nil
+	case Bytes:
+		b, ok := av.Val.([]byte)
+		if !ok {
+			return nil, &typeError{[]byte{}, av.Val}
+		}
+
+		return appendMsgpackBin(buf, b), nil
+	case String:
+		s, ok := av.Val.(string)
+		if !ok {
+			return nil, &typeError{"", av.Val}
+		}
+
+		return appendMsgpackStr(buf, s), nil
+	case Boolean:
+		b, ok := av.Val.(bool)
+		if !ok {
+			return nil, &typeError{false, av.Val}
+		}
+
+		if b {
+			return append(buf, msgpackTrue), nil
+		}
+		return append(buf, msgpackFalse), nil
+	case SectorID, Uint64:
+		n, ok := av.Val.(uint64)
+		if !ok {
+			return nil, &typeError{uint64(0), av.Val}
+		}
+
+		return appendMsgpackUint(buf, n), nil
+	default:
+		// everything else is carried as the raw serialized bytes
+		data, err := av.Serialize()
+		if err != nil {
+			return nil, err
+		}
+
+		return appendMsgpackBin(buf, data), nil
+	}
+}
+
+// UnmarshalMsgpack decodes a value produced by MarshalMsgpack and checks that
+// it carries the given type.
+func UnmarshalMsgpack(data []byte, t Type) (*Value, error) {
+	if t == Invalid {
+		return nil, ErrInvalidType
+	}
+
+	if len(data) == 0 {
+		return nil, errMsgpackTruncated
+	}
+	if data[0] != msgpackFixArray|2 {
+		return nil, fmt.Errorf("msgpack: expected a two element array")
+	}
+
+	n, data, err := readMsgpackUint(data[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	vt := Type(n)
+	if vt != t {
+		return nil, fmt.Errorf("msgpack: expected value of type %s, got %s", t, vt)
+	}
+
+	var av *Value
+	var rest []byte
+	switch t {
+	case Integer:
+		var s []byte
+		s, rest, err = readMsgpackStr(data)
+		if err != nil {
+			return nil, err
+		}
+
+		intgr, ok := new(big.Int).SetString(string(s), 10)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: invalid integer: %q", s)
+		}
+		if err := checkIntegerBits(intgr); err != nil {
+			return nil, fmt.Errorf("msgpack: %s", err)
+		}
+		av = &Value{Type: t, Val: intgr}
+	case Bytes:
+		var b []byte
+		b, rest, err = readMsgpackBin(data)
+		if err != nil {
+			return nil, err
+		}
+
+		av = &Value{Type: t, Val: append([]byte{}, b...)}
+	case String:
+		var s []byte
+		s, rest, err = readMsgpackStr(data)
+		if err != nil {
+			return nil, err
+		}
+
+		av = &Value{Type: t, Val: string(s)}
+	case Boolean:
+		if len(data) == 0 {
+			return nil, errMsgpackTruncated
+		}
+		if data[0] != msgpackFalse && data[0] != msgpackTrue {
+			return nil, fmt.Errorf("msgpack: expected a boolean")
+		}
+
+		av, rest = &Value{Type: t, Val: data[0] == msgpackTrue}, data[1:]
+	case SectorID, Uint64:
+		var n uint64
+		n, rest, err = readMsgpackUint(data)
+		if err != nil {
+			return nil, err
+		}
+
+		av = &Value{Type: t, Val: n}
+	default:
+		var raw []byte
+		raw, rest, err = readMsgpackBin(data)
+		if err != nil {
+			return nil, err
+		}
+
+		av, err = Deserialize(raw, t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("msgpack: %d trailing bytes after value", len(rest))
+	}
+
+	return av, nil
+}
+
+func appendMsgpackUint(buf []byte, n uint64) []byte {
+	switch {
+	case n < 0x80:
+		return append(buf, byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, msgpackUint8, byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, msgpackUint16, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(buf, msgpackUint32, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return append(buf, msgpackUint64,
+			byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+			byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func readMsgpackUint(data []byte) (uint64, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, errMsgpackTruncated
+	}
+
+	var size int
+	switch f := data[0]; {
+	case f < 0x80:
+		return uint64(f), data[1:], nil
+	case f == msgpackUint8:
+		size = 1
+	case f == msgpackUint16:
+		size = 2
+	case f == msgpackUint32:
+		size = 4
+	case f == msgpackUint64:
+		size = 8
+	default:
+		return 0, nil, fmt.Errorf("msgpack: expected an unsigned int, got format %#x", f)
+	}
+
+	n, rest, err := readMsgpackLength(data[1:], size)
+	return uint64(n), rest, err
+}
+
+func appendMsgpackStr(buf []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		buf = append(buf, msgpackFixStr|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, msgpackStr8, byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, msgpackStr16, byte(n>>8), byte(n))
+	default:
+		buf = append(buf, msgpackStr32, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(buf, s...)
+}
+
+func readMsgpackStr(data []byte) ([]byte, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, errMsgpackTruncated
+	}
+
+	var size int
+	switch f := data[0]; {
+	case f&0xe0 == msgpackFixStr:
+		return readMsgpackPayload(data[1:], uint64(f&0x1f))
+	case f == msgpackStr8:
+		size = 1
+	case f == msgpackStr16:
+		size = 2
+	case f == msgpackStr32:
+		size = 4
+	default:
+		return nil, nil, fmt.Errorf("msgpack: expected a string, got format %#x", f)
+	}
+
+	n, rest, err := readMsgpackLength(data[1:], size)
+	if err != nil {
+		return nil, nil, err
+	}
+	return readMsgpackPayload(rest, n)
+}
+
+func appendMsgpackBin(buf []byte, b []byte) []byte {
+	switch n := len(b); {
+	case n <= math.MaxUint8:
+		buf = append(buf, msgpackBin8, byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, msgpackBin16, byte(n>>8), byte(n))
+	default:
+		buf = append(buf, msgpackBin32, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(buf, b...)
+}
+
+func readMsgpackBin(data []byte) ([]byte, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, errMsgpackTruncated
+	}
+
+	var size int
+	switch data[0] {
+	case msgpackBin8:
+		size = 1
+	case msgpackBin16:
+		size = 2
+	case msgpackBin32:
+		size = 4
+	default:
+		return nil, nil, fmt.Errorf("msgpack: expected binary data, got format %#x", data[0])
+	}
+
+	n, rest, err := readMsgpackLength(data[1:], size)
+	if err != nil {
+		return nil, nil, err
+	}
+	return readMsgpackPayload(rest, n)
+}
+
+// readMsgpackLength reads a big endian number of the given size in bytes.
+func readMsgpackLength(data []byte, size int) (uint64, []byte, error) {
+	if len(data) < size {
+		return 0, nil, errMsgpackTruncated
+	}
+
+	var n uint64
+	for _, b := range data[:size] {
+		n = n<<8 | uint64(b)
+	}
+	return n, data[size:], nil
+}
+
+func readMsgpackPayload(data []byte, n uint64) ([]byte, []byte, error) {
+	if uint64(len(data)) < n {
+		return nil, nil, errMsgpackTruncated
+	}
+	return data[:n], data[n:], nil
+}
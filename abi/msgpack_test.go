@@ -0,0 +1,98 @@
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	addrGetter := address.NewForTestGetter()
+
+	huge, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+
+	cases := map[string]*Value{
+		"address":     {Type: Address, Val: addrGetter()},
+		"string":      {Type: String, Val: "flugzeug"},
+		"long string": {Type: String, Val: strings.Repeat("a", 300)},
+		"bytes":       {Type: Bytes, Val: []byte("beep")},
+		"empty bytes": {Type: Bytes, Val: []byte{}},
+		"huge int":    {Type: Integer, Val: huge},
+		"boolean":     {Type: Boolean, Val: false},
+		"sector id":   {Type: SectorID, Val: uint64(1234)},
+		"uint64":      {Type: Uint64, Val: uint64(1 << 40)},
+	}
+
+	for tname, tcase := range cases {
+		t.Run(tname, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			data, err := tcase.MarshalMsgpack()
+			require.NoError(err)
+
+			out, err := UnmarshalMsgpack(data, tcase.Type)
+			require.NoError(err)
+			assert.True(tcase.Equals(out), "%s != %s", tcase, out)
+
+			// the json codec must agree on the decoded value
+			js, err := json.Marshal(tcase)
+			require.NoError(err)
+			var jsOut Value
+			require.NoError(json.Unmarshal(js, &jsOut))
+			assert.True(out.Equals(&jsOut), "%s != %s", out, &jsOut)
+		})
+	}
+}
+
+func TestMsgpackIntegerEncoding(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := (&Value{Type: Integer, Val: big.NewInt(-17)}).MarshalMsgpack()
+	assert.NoError(err)
+	assert.Equal([]byte{0x92, 0x06, 0xa3, '-', '1', '7'}, data)
+}
+
+func TestUnmarshalMsgpackFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := (&Value{Type: String, Val: "foo"}).MarshalMsgpack()
+	assert.NoError(err)
+
+	_, err = UnmarshalMsgpack(data, Bytes)
+	assert.EqualError(err, "msgpack: expected value of type []byte, got string")
+
+	_, err = UnmarshalMsgpack(data[:len(data)-1], String)
+	assert.Equal(errMsgpackTruncated, err)
+
+	_, err = UnmarshalMsgpack(append(data, 0), String)
+	assert.EqualError(err, "msgpack: 1 trailing bytes after value")
+
+	_, err = UnmarshalMsgpack(data, Invalid)
+	assert.Equal(ErrInvalidType, err)
+
+	_, err = UnmarshalMsgpack([]byte{0x92, 0x06, 0xa1, 'x'}, Integer)
+	assert.EqualError(err, `msgpack: invalid integer: "x"`)
+
+	_, err = (*Value)(nil).MarshalMsgpack()
+	assert.Equal(ErrNilValue, err)
+}
+
+func TestUnmarshalMsgpackIntegerBitLimit(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tooLarge := new(big.Int).Lsh(big.NewInt(1), uint(MaxIntegerBits))
+	data, err := (&Value{Type: Integer, Val: tooLarge}).MarshalMsgpack()
+	require.NoError(err)
+
+	_, err = UnmarshalMsgpack(data, Integer)
+	assert.EqualError(err, fmt.Sprintf("msgpack: integer too large: %d bits exceeds limit of %d", MaxIntegerBits+1, MaxIntegerBits))
+}
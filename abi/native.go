@@ -0,0 +1,69 @@
+package abi
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ToMap returns a generic view of the value for template engines, holding
+// its type name under "type" and its value under "value". Arbitrary precision
+// numbers are rendered like Format does, everything else is a copy of the go
+// value. ValueFromMap is the inverse.
+func (av *Value) ToMap() map[string]interface{} {
+	if av == nil {
+		return nil
+	}
+
+	var val interface{}
+	if isTextual(av.Type) {
+		val = av.Format()
+	} else {
+		val = av.Clone().Val
+	}
+
+	return map[string]interface{}{
+		"type":  av.Type.String(),
+		"value": val,
+	}
+}
+
+// ValueFromMap converts a map produced by ToMap back into a value.
+func ValueFromMap(m map[string]interface{}) (*Value, error) {
+	name, ok := m["type"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing type name")
+	}
+	t, err := TypeFromString(name)
+	if err != nil {
+		return nil, err
+	}
+
+	val, ok := m["value"]
+	if !ok {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	if isTextual(t) {
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected %s value as a string, got %T", t, val)
+		}
+		return ParseValue(t, s)
+	}
+
+	if rt, ok := typeTable[t]; ok && reflect.TypeOf(val) != rt {
+		return nil, &typeError{reflect.Zero(rt).Interface(), val}
+	}
+	return (&Value{Type: t, Val: val}).Clone(), nil
+}
+
+// isTextual reports whether values of the type are represented as strings by
+// ToMap.
+func isTextual(t Type) bool {
+	switch t {
+	case AttoFIL, BytesAmount, ChannelID, BlockHeight, Integer, Int256, Rational:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,61 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapFormRoundTrip(t *testing.T) {
+	for name, v := range canonicalValues(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			m := v.ToMap()
+			assert.Equal(v.Type.String(), m["type"])
+
+			out, err := ValueFromMap(m)
+			require.NoError(err)
+			assert.True(v.Equals(out), "expected %s, got %s", v, out)
+		})
+	}
+}
+
+func TestMapFormValues(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewInteger(big.NewInt(-42)).ToMap()
+	assert.Equal(map[string]interface{}{"type": "*big.Int", "value": "-42"}, m)
+
+	b := []byte("beep")
+	m = NewBytes(b).ToMap()
+	assert.Equal([]byte("beep"), m["value"])
+
+	// the map doesn't alias the value
+	m["value"].([]byte)[0] = 'x'
+	assert.Equal([]byte("beep"), b)
+
+	assert.Nil((*Value)(nil).ToMap())
+}
+
+func TestValueFromMapFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ValueFromMap(map[string]interface{}{"value": "foo"})
+	assert.EqualError(err, "missing type name")
+
+	_, err = ValueFromMap(map[string]interface{}{"type": "complex128", "value": "foo"})
+	assert.EqualError(err, `unknown type: "complex128"`)
+
+	_, err = ValueFromMap(map[string]interface{}{"type": "string"})
+	assert.EqualError(err, "missing value")
+
+	_, err = ValueFromMap(map[string]interface{}{"type": "*big.Int", "value": 42})
+	assert.EqualError(err, "expected *big.Int value as a string, got int")
+
+	_, err = ValueFromMap(map[string]interface{}{"type": "string", "value": 42})
+	assert.EqualError(err, "expected type string, got int")
+}
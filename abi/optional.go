@@ -0,0 +1,40 @@
+package abi
+
+import (
+	"fmt"
+)
+
+const (
+	optionalAbsent  = 0x00
+	optionalPresent = 0x01
+)
+
+// SerializeOptional serializes a value that may be absent. The encoding is
+// prefixed with a presence byte, a nil value is encoded as the lone byte 0x00.
+func SerializeOptional(av *Value) ([]byte, error) {
+	if av == nil {
+		return []byte{optionalAbsent}, nil
+	}
+
+	return av.SerializeAppend([]byte{optionalPresent})
+}
+
+// DeserializeOptional decodes a value produced by SerializeOptional. It
+// returns a nil value and no error if the value is absent.
+func DeserializeOptional(data []byte, t Type) (*Value, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("invalid optional encoding: missing presence byte")
+	}
+
+	switch data[0] {
+	case optionalAbsent:
+		if len(data) != 1 {
+			return nil, fmt.Errorf("invalid optional encoding: %d bytes after absent value", len(data)-1)
+		}
+		return nil, nil
+	case optionalPresent:
+		return Deserialize(data[1:], t)
+	default:
+		return nil, fmt.Errorf("invalid optional encoding: unknown presence byte %#x", data[0])
+	}
+}
@@ -0,0 +1,59 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionalRoundTrip(t *testing.T) {
+	for name, v := range map[string]*Value{
+		"present":      {Type: Integer, Val: big.NewInt(42)},
+		"present zero": {Type: Integer, Val: big.NewInt(0)},
+		"empty string": {Type: String, Val: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			data, err := SerializeOptional(v)
+			require.NoError(err)
+			assert.Equal(byte(optionalPresent), data[0])
+
+			out, err := DeserializeOptional(data, v.Type)
+			require.NoError(err)
+			require.NotNil(out)
+			assert.True(v.Equals(out), "expected %s, got %s", v, out)
+		})
+	}
+}
+
+func TestOptionalAbsent(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := SerializeOptional(nil)
+	assert.NoError(err)
+	assert.Equal([]byte{0x00}, data)
+
+	out, err := DeserializeOptional(data, Integer)
+	assert.NoError(err)
+	assert.Nil(out)
+}
+
+func TestOptionalFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := DeserializeOptional(nil, Integer)
+	assert.EqualError(err, "invalid optional encoding: missing presence byte")
+
+	_, err = DeserializeOptional([]byte{0x00, 0x00}, Integer)
+	assert.EqualError(err, "invalid optional encoding: 1 bytes after absent value")
+
+	_, err = DeserializeOptional([]byte{0x02}, Integer)
+	assert.EqualError(err, "invalid optional encoding: unknown presence byte 0x2")
+
+	_, err = SerializeOptional(&Value{Type: Integer, Val: "foo"})
+	assert.EqualError(err, "expected type *big.Int, got string")
+}
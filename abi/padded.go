@@ -0,0 +1,57 @@
+package abi
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// SerializePadded encodes a Bytes value with its bytes right-padded with zeros
+// up to a multiple of align. The original length is stored first, as a
+// uvarint, so that DeserializePadded can restore the exact bytes.
+func SerializePadded(av *Value, align int) ([]byte, error) {
+	if align <= 0 {
+		return nil, fmt.Errorf("invalid alignment: %d", align)
+	}
+
+	b, err := av.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	padded := (len(b) + align - 1) / align * align
+
+	var buf [binary.MaxVarintLen64]byte
+	out := make([]byte, 0, binary.MaxVarintLen64+padded)
+	out = append(out, buf[:binary.PutUvarint(buf[:], uint64(len(b)))]...)
+	out = append(out, b...)
+	return append(out, make([]byte, padded-len(b))...), nil
+}
+
+// DeserializePadded decodes a Bytes value encoded by SerializePadded with the
+// same alignment. It errors if the padding is malformed.
+func DeserializePadded(data []byte, align int) (*Value, error) {
+	if align <= 0 {
+		return nil, fmt.Errorf("invalid alignment: %d", align)
+	}
+
+	n, k := binary.Uvarint(data)
+	if k <= 0 {
+		return nil, fmt.Errorf("invalid length prefix")
+	}
+	data = data[k:]
+
+	if len(data)%align != 0 {
+		return nil, fmt.Errorf("padded length %d is not a multiple of %d", len(data), align)
+	}
+	if n > uint64(len(data)) || uint64(len(data))-n >= uint64(align) {
+		return nil, fmt.Errorf("length %d does not match padded length %d", n, len(data))
+	}
+
+	for _, p := range data[n:] {
+		if p != 0 {
+			return nil, fmt.Errorf("invalid padding: non zero byte")
+		}
+	}
+
+	return Deserialize(data[:n], Bytes)
+}
@@ -0,0 +1,79 @@
+package abi
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaddedRoundTrip(t *testing.T) {
+	for name, b := range map[string][]byte{
+		"short":   []byte("beeps"),
+		"aligned": bytes.Repeat([]byte{0xab}, 64),
+		"empty":   {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			data, err := SerializePadded(NewBytes(b), 32)
+			require.NoError(err)
+
+			// one byte of length prefix, then the padded bytes
+			assert.Equal(0, (len(data)-1)%32)
+			assert.True(len(data)-1-len(b) < 32)
+
+			out, err := DeserializePadded(data, 32)
+			require.NoError(err)
+			assert.Equal(b, out.Val)
+		})
+	}
+}
+
+func TestPaddedLayout(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := SerializePadded(NewBytes([]byte("beeps")), 32)
+	assert.NoError(err)
+	assert.Len(data, 33)
+	assert.Equal(byte(5), data[0])
+	assert.Equal([]byte("beeps"), data[1:6])
+	assert.Equal(make([]byte, 27), data[6:])
+}
+
+func TestPaddedFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := SerializePadded(NewBytes([]byte("beep")), 0)
+	assert.EqualError(err, "invalid alignment: 0")
+
+	_, err = DeserializePadded([]byte{0}, 0)
+	assert.EqualError(err, "invalid alignment: 0")
+
+	_, err = SerializePadded(NewString("beep"), 32)
+	assert.EqualError(err, "expected value of type []byte, got string")
+
+	data, err := SerializePadded(NewBytes([]byte("beeps")), 8)
+	assert.NoError(err)
+
+	_, err = DeserializePadded(data[:len(data)-1], 8)
+	assert.EqualError(err, "padded length 7 is not a multiple of 8")
+
+	bad := append([]byte{}, data...)
+	bad[len(bad)-1] = 1
+	_, err = DeserializePadded(bad, 8)
+	assert.EqualError(err, "invalid padding: non zero byte")
+
+	bad[0] = 9
+	_, err = DeserializePadded(bad, 8)
+	assert.EqualError(err, "length 9 does not match padded length 8")
+
+	// too much padding
+	_, err = DeserializePadded(append([]byte{1, 'a'}, make([]byte, 15)...), 8)
+	assert.EqualError(err, "length 1 does not match padded length 16")
+
+	_, err = DeserializePadded(nil, 8)
+	assert.EqualError(err, "invalid length prefix")
+}
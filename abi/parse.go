@@ -0,0 +1,272 @@
+package abi
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gx/ipfs/QmR8BauakNcBa3RbE4nbQu76PDiJgoQgz8AJdhJuiU4TAw/go-cid"
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+	"gx/ipfs/QmY5Grm8pJdiSSVsYxx4uNRgweY72EmYwuSDbRnbFok3iY/go-libp2p-peer"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// BytesEncoding selects the textual encoding of Bytes values used by
+// ParseValueEncoded and FormatEncoded.
+type BytesEncoding int
+
+const (
+	// FormatHex encodes bytes as hex with a 0x prefix, which is optional when
+	// parsing.
+	FormatHex = BytesEncoding(iota)
+	// FormatBase64 encodes bytes as padded standard base64.
+	FormatBase64
+	// FormatBase32 encodes bytes as padded standard base32.
+	FormatBase32
+)
+
+// ParseValue interprets a command line argument as a value of the given type.
+// Bytes are read as hex with an optional 0x prefix, numbers as decimals,
+// AttoFIL as an amount of FIL, address sets as comma separated addresses and
+// strings are taken as is. Format is the inverse.
+func ParseValue(t Type, s string) (*Value, error) {
+	return ParseValueEncoded(t, s, FormatHex)
+}
+
+// ParseValueEncoded works like ParseValue, but reads Bytes values in the given
+// encoding.
+func ParseValueEncoded(t Type, s string, enc BytesEncoding) (*Value, error) {
+	var val interface{}
+	switch t {
+	case Invalid:
+		return nil, ErrInvalidType
+	case Address:
+		addr, err := address.NewFromString(s)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid address")
+		}
+		val = addr
+	case AttoFIL:
+		amount, ok := types.NewAttoFILFromFILString(s)
+		if !ok {
+			return nil, fmt.Errorf("invalid token amount: %q", s)
+		}
+		val = amount
+	case BytesAmount:
+		amount, ok := types.NewBytesAmountFromString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid bytes amount: %q", s)
+		}
+		val = amount
+	case ChannelID:
+		id, ok := types.NewChannelIDFromString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid channel id: %q", s)
+		}
+		val = id
+	case BlockHeight:
+		h, ok := types.NewBlockHeightFromString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid block height: %q", s)
+		}
+		val = h
+	case Integer, Int256:
+		intgr, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer: %q", s)
+		}
+		if t == Int256 && (intgr.Sign() < 0 || intgr.BitLen() > 256) {
+			return nil, fmt.Errorf("integer out of range for int256: %s", s)
+		}
+		val = intgr
+	case Bytes:
+		b, err := parseBytes(s, enc)
+		if err != nil {
+			return nil, err
+		}
+		val = b
+	case String:
+		val = s
+	case PeerID:
+		id, err := peer.IDB58Decode(s)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid peer id")
+		}
+		val = id
+	case SectorID, Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid uint64")
+		}
+		val = n
+	case Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid int64")
+		}
+		val = n
+	case Boolean:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid boolean")
+		}
+		val = b
+	case Cid:
+		c, err := cid.Decode(s)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cid")
+		}
+		val = c
+	case Rational:
+		rat, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return nil, fmt.Errorf("invalid rational: %q", s)
+		}
+		val = rat
+	case Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid float64")
+		}
+		val = f
+	case AddressSet:
+		addrs := []address.Address{}
+		if s != "" {
+			for _, as := range strings.Split(s, ",") {
+				addr, err := address.NewFromString(as)
+				if err != nil {
+					return nil, errors.Wrap(err, "invalid address")
+				}
+				addrs = append(addrs, addr)
+			}
+		}
+		val = addrs
+	default:
+		return nil, fmt.Errorf("cannot parse values of type %s", t)
+	}
+
+	return &Value{Type: t, Val: val}, nil
+}
+
+// Format renders the value the way ParseValue expects it. Values of types that
+// can't be parsed, and malformed values, are rendered like String does.
+func (av *Value) Format() string {
+	return av.FormatEncoded(FormatHex)
+}
+
+// FormatEncoded works like Format, but renders Bytes values in the given
+// encoding.
+func (av *Value) FormatEncoded(enc BytesEncoding) string {
+	if av.Validate() != nil {
+		return av.String()
+	}
+
+	switch v := av.Val.(type) {
+	case []byte:
+		switch enc {
+		case FormatBase64:
+			return base64.StdEncoding.EncodeToString(v)
+		case FormatBase32:
+			return base32.StdEncoding.EncodeToString(v)
+		default:
+			return "0x" + hex.EncodeToString(v)
+		}
+	case string:
+		return v
+	case peer.ID:
+		return peer.IDB58Encode(v)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case *big.Rat:
+		return v.RatString()
+	case []address.Address:
+		strs := make([]string, len(v))
+		for i, addr := range v {
+			strs[i] = addr.String()
+		}
+		return strings.Join(strs, ",")
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return av.String()
+	}
+}
+
+// TypedValue is a Value with a fixed type, which lets it be unmarshaled from
+// text. It implements encoding.TextMarshaler, encoding.TextUnmarshaler and
+// flag.Value using ParseValue and Format.
+type TypedValue struct {
+	Type Type
+	*Value
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (tv *TypedValue) MarshalText() ([]byte, error) {
+	if tv.Value == nil {
+		return nil, ErrNilValue
+	}
+	if tv.Value.Type != tv.Type {
+		return nil, fmt.Errorf("expected value of type %s, got %s", tv.Type, tv.Value.Type)
+	}
+	return []byte(tv.Format()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (tv *TypedValue) UnmarshalText(text []byte) error {
+	v, err := ParseValue(tv.Type, string(text))
+	if err != nil {
+		return err
+	}
+	tv.Value = v
+	return nil
+}
+
+// String implements flag.Value.
+func (tv *TypedValue) String() string {
+	if tv == nil || tv.Value == nil {
+		return ""
+	}
+	return tv.Format()
+}
+
+// Set implements flag.Value.
+func (tv *TypedValue) Set(s string) error {
+	return tv.UnmarshalText([]byte(s))
+}
+
+func parseBytes(s string, enc BytesEncoding) ([]byte, error) {
+	switch enc {
+	case FormatHex:
+		b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid hex")
+		}
+		return b, nil
+	case FormatBase64:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid base64")
+		}
+		return b, nil
+	case FormatBase32:
+		b, err := base32.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid base32")
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown bytes encoding: %d", enc)
+	}
+}
@@ -0,0 +1,202 @@
+package abi
+
+import (
+	"flag"
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseValue(t *testing.T) {
+	addr := address.NewForTestGetter()()
+
+	cases := []struct {
+		typ Type
+		in  string
+		val interface{}
+		out string
+	}{
+		{Address, addr.String(), addr, addr.String()},
+		{AttoFIL, "1.5", types.NewAttoFILFromFIL(1).Add(types.NewAttoFIL(big.NewInt(500000000000000000))), "1.5"},
+		{BytesAmount, "1024", types.NewBytesAmount(1024), "1024"},
+		{ChannelID, "7", types.NewChannelID(7), "7"},
+		{BlockHeight, "1000", types.NewBlockHeight(1000), "1000"},
+		{Integer, "-1234", big.NewInt(-1234), "-1234"},
+		{Int256, "255", big.NewInt(255), "255"},
+		{Bytes, "0xdeadbeef", []byte{0xde, 0xad, 0xbe, 0xef}, "0xdeadbeef"},
+		{Bytes, "DEADBEEF", []byte{0xde, 0xad, 0xbe, 0xef}, "0xdeadbeef"},
+		{Bytes, "", []byte{}, "0x"},
+		{String, "0xdeadbeef", "0xdeadbeef", "0xdeadbeef"},
+		{SectorID, "42", uint64(42), "42"},
+		{Uint64, "18446744073709551615", uint64(18446744073709551615), "18446744073709551615"},
+		{Boolean, "true", true, "true"},
+		{Int64, "-9223372036854775808", int64(-9223372036854775808), "-9223372036854775808"},
+		{Rational, "2/6", big.NewRat(1, 3), "1/3"},
+		{Float64, "1.5", 1.5, "1.5"},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.typ.String()+"/"+tcase.in, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			v, err := ParseValue(tcase.typ, tcase.in)
+			require.NoError(err)
+			expected := &Value{Type: tcase.typ, Val: tcase.val}
+			assert.True(expected.Equals(v), "expected %s, got %s", expected, v)
+			assert.Equal(tcase.out, v.Format())
+
+			again, err := ParseValue(tcase.typ, v.Format())
+			require.NoError(err)
+			assert.True(v.Equals(again))
+		})
+	}
+}
+
+func TestParseValueFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ParseValue(Bytes, "0xabc")
+	assert.EqualError(err, "invalid hex: encoding/hex: odd length hex string")
+
+	_, err = ParseValue(Bytes, "xyz")
+	assert.Error(err)
+
+	_, err = ParseValue(Integer, "1.5")
+	assert.EqualError(err, `invalid integer: "1.5"`)
+
+	_, err = ParseValue(Int256, "-1")
+	assert.EqualError(err, "integer out of range for int256: -1")
+
+	_, err = ParseValue(Int256, new(big.Int).Lsh(big.NewInt(1), 256).String())
+	assert.Error(err)
+
+	_, err = ParseValue(Uint64, "18446744073709551616")
+	assert.Error(err)
+
+	_, err = ParseValue(Address, "foo")
+	assert.Error(err)
+
+	_, err = ParseValue(UintArray, "1,2")
+	assert.EqualError(err, "cannot parse values of type []uint64")
+
+	_, err = ParseValue(Invalid, "")
+	assert.Equal(ErrInvalidType, err)
+}
+
+func TestFormatMalformedValue(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("<nil>", (*Value)(nil).Format())
+	assert.Equal("*big.Int(foo)", (&Value{Type: Integer, Val: "foo"}).Format())
+}
+
+func TestParseValueEncoded(t *testing.T) {
+	assert := assert.New(t)
+
+	b := []byte{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01}
+	v := &Value{Type: Bytes, Val: b}
+
+	for enc, expected := range map[BytesEncoding]string{
+		FormatHex:    "0xdeadbeef0001",
+		FormatBase64: "3q2+7wAB",
+		FormatBase32: "32W353YAAE======",
+	} {
+		s := v.FormatEncoded(enc)
+		assert.Equal(expected, s)
+
+		out, err := ParseValueEncoded(Bytes, s, enc)
+		assert.NoError(err)
+		assert.Equal(b, out.Val)
+	}
+
+	// the encoding only applies to Bytes
+	out, err := ParseValueEncoded(String, "3q2+7wAB", FormatBase64)
+	assert.NoError(err)
+	assert.Equal("3q2+7wAB", out.Val)
+}
+
+func TestParseValueEncodedFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ParseValueEncoded(Bytes, "3q2+7wA", FormatBase64)
+	assert.Error(err)
+	assert.Contains(err.Error(), "invalid base64")
+
+	_, err = ParseValueEncoded(Bytes, "32W353YAAE", FormatBase32)
+	assert.Error(err)
+	assert.Contains(err.Error(), "invalid base32")
+
+	_, err = ParseValueEncoded(Bytes, "0xdeadbee", FormatHex)
+	assert.Error(err)
+	assert.Contains(err.Error(), "invalid hex")
+
+	_, err = ParseValueEncoded(Bytes, "", BytesEncoding(17))
+	assert.EqualError(err, "unknown bytes encoding: 17")
+}
+
+func TestTypedValueText(t *testing.T) {
+	addr := address.NewForTestGetter()()
+
+	for typ, text := range map[Type]string{
+		Address:     addr.String(),
+		AttoFIL:     "1.5",
+		BytesAmount: "1024",
+		ChannelID:   "7",
+		BlockHeight: "1000",
+		Integer:     "-1234",
+		Int256:      "255",
+		Bytes:       "0xdeadbeef",
+		String:      "flugzeug",
+		SectorID:    "42",
+		Uint64:      "42",
+		Boolean:     "true",
+		Int64:       "-42",
+		Rational:    "1/3",
+		Float64:     "1.5",
+	} {
+		assert := assert.New(t)
+
+		tv := &TypedValue{Type: typ}
+		assert.NoError(tv.UnmarshalText([]byte(text)), typ.String())
+		assert.Equal(typ, tv.Value.Type)
+
+		out, err := tv.MarshalText()
+		assert.NoError(err, typ.String())
+		assert.Equal(text, string(out))
+	}
+}
+
+func TestTypedValueFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	tv := &TypedValue{Type: Integer}
+	_, err := tv.MarshalText()
+	assert.Equal(ErrNilValue, err)
+	assert.Equal("", tv.String())
+
+	assert.EqualError(tv.UnmarshalText([]byte("foo")), `invalid integer: "foo"`)
+	assert.Nil(tv.Value)
+
+	tv.Value = NewString("foo")
+	_, err = tv.MarshalText()
+	assert.EqualError(err, "expected value of type *big.Int, got string")
+}
+
+func TestTypedValueFlag(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	param := &TypedValue{Type: Bytes}
+	fs.Var(param, "param", "a parameter")
+
+	require.NoError(fs.Parse([]string{"--param=0xdead"}))
+	assert.Equal([]byte{0xde, 0xad}, param.Val)
+	assert.Equal("0xdead", param.String())
+}
@@ -0,0 +1,28 @@
+package abi
+
+import (
+	"sync"
+)
+
+var valuePool = sync.Pool{
+	New: func() interface{} {
+		return new(Value)
+	},
+}
+
+// GetValue returns a zeroed Value from a shared pool, e.g. to be filled by
+// DeserializeInto. Hand it back with PutValue once it is no longer needed.
+func GetValue() *Value {
+	return valuePool.Get().(*Value)
+}
+
+// PutValue clears av and returns it to the pool used by GetValue. Neither av
+// nor anything obtained from it may be used after the call.
+func PutValue(av *Value) {
+	if av == nil {
+		return
+	}
+
+	*av = Value{}
+	valuePool.Put(av)
+}
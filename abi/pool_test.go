@@ -0,0 +1,68 @@
+package abi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValuePool(t *testing.T) {
+	assert := assert.New(t)
+
+	v := GetValue()
+	assert.Equal(&Value{}, v)
+
+	v.Type = Bytes
+	v.Val = []byte("beep")
+	PutValue(v)
+
+	// whether or not we get the same struct back, it must be zeroed
+	v = GetValue()
+	assert.Equal(&Value{}, v)
+	PutValue(v)
+
+	PutValue(nil)
+}
+
+func TestDeserializeInto(t *testing.T) {
+	assert := assert.New(t)
+
+	v := GetValue()
+	defer PutValue(v)
+
+	assert.NoError(DeserializeInto([]byte{1}, Boolean, v))
+	assert.Equal(&Value{Type: Boolean, Val: true}, v)
+
+	// failures leave the value untouched
+	assert.Error(DeserializeInto([]byte{2}, Boolean, v))
+	assert.Equal(&Value{Type: Boolean, Val: true}, v)
+
+	assert.Equal(ErrNilValue, DeserializeInto([]byte{1}, Boolean, nil))
+}
+
+var sinkValue *Value
+
+func BenchmarkDecodeLoop(b *testing.B) {
+	data := []byte{1}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v, err := Deserialize(data, Boolean)
+		if err != nil {
+			b.Fatal(err)
+		}
+		sinkValue = v
+	}
+}
+
+func BenchmarkDecodeLoopPooled(b *testing.B) {
+	data := []byte{1}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v := GetValue()
+		if err := DeserializeInto(data, Boolean, v); err != nil {
+			b.Fatal(err)
+		}
+		sinkValue = v
+		PutValue(v)
+	}
+}
@@ -0,0 +1,107 @@
+package abi
+
+import (
+	"fmt"
+	"sync"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+)
+
+// ErrNoCodec is returned when serializing or deserializing a type that was
+// declared with DeclareType but has no codec registered.
+var ErrNoCodec = fmt.Errorf("no codec registered")
+
+// Codec encodes and decodes the go values of a custom ABI type.
+type Codec interface {
+	Encode(interface{}) ([]byte, error)
+	Decode([]byte) (interface{}, error)
+}
+
+var (
+	registryLk sync.RWMutex
+	registry   = map[Type]Codec{}
+	declared   = map[Type]string{}
+)
+
+// DeclareType gives a custom type a name, which is returned by Type.String and
+// understood by TypeFromString. A codec for it can be registered separately
+// with RegisterType. Names must be unique.
+func DeclareType(t Type, name string) error {
+	if t == Invalid {
+		return ErrInvalidType
+	}
+	if _, ok := typeTable[t]; ok {
+		return fmt.Errorf("cannot declare built-in type %s", t)
+	}
+	for bt := range typeTable {
+		if bt.String() == name {
+			return fmt.Errorf("type name %q is already in use", name)
+		}
+	}
+
+	registryLk.Lock()
+	defer registryLk.Unlock()
+
+	if _, ok := declared[t]; ok {
+		return fmt.Errorf("type %d is already declared", t)
+	}
+	for _, n := range declared {
+		if n == name {
+			return fmt.Errorf("type name %q is already in use", name)
+		}
+	}
+	declared[t] = name
+	return nil
+}
+
+// RegisterType registers a codec for a custom type, which makes Serialize and
+// Deserialize support it. Built-in types can't be overridden and every type
+// can only be registered once.
+func RegisterType(t Type, codec Codec) error {
+	if t == Invalid {
+		return ErrInvalidType
+	}
+	if _, ok := typeTable[t]; ok {
+		return fmt.Errorf("cannot register built-in type %s", t)
+	}
+	if codec == nil {
+		return fmt.Errorf("cannot register nil codec for type %d", t)
+	}
+
+	registryLk.Lock()
+	defer registryLk.Unlock()
+
+	if _, ok := registry[t]; ok {
+		return fmt.Errorf("type %d is already registered", t)
+	}
+	registry[t] = codec
+	return nil
+}
+
+func registeredCodec(t Type) (Codec, bool) {
+	registryLk.RLock()
+	defer registryLk.RUnlock()
+
+	codec, ok := registry[t]
+	return codec, ok
+}
+
+func declaredName(t Type) (string, bool) {
+	registryLk.RLock()
+	defer registryLk.RUnlock()
+
+	name, ok := declared[t]
+	return name, ok
+}
+
+// customCodec returns the codec for a type that isn't built-in. Types that are
+// declared but have no codec yield ErrNoCodec.
+func customCodec(t Type) (Codec, error) {
+	if codec, ok := registeredCodec(t); ok {
+		return codec, nil
+	}
+	if name, ok := declaredName(t); ok {
+		return nil, errors.Wrapf(ErrNoCodec, "type %s", name)
+	}
+	return nil, fmt.Errorf("unrecognized Type: %d", t)
+}
@@ -0,0 +1,147 @@
+package abi
+
+import (
+	"fmt"
+	"testing"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bitfieldCodec encodes a []bool as one byte per bit, for testing custom types.
+type bitfieldCodec struct{}
+
+func (bitfieldCodec) Encode(v interface{}) ([]byte, error) {
+	bits, ok := v.([]bool)
+	if !ok {
+		return nil, &typeError{[]bool{}, v}
+	}
+
+	out := make([]byte, len(bits))
+	for i, b := range bits {
+		if b {
+			out[i] = 1
+		}
+	}
+	return out, nil
+}
+
+func (bitfieldCodec) Decode(data []byte) (interface{}, error) {
+	out := make([]bool, len(data))
+	for i, b := range data {
+		if b > 1 {
+			return nil, fmt.Errorf("invalid bit %d", b)
+		}
+		out[i] = b == 1
+	}
+	return out, nil
+}
+
+func unregisterType(t Type) {
+	registryLk.Lock()
+	defer registryLk.Unlock()
+	delete(registry, t)
+}
+
+func TestRegisterTypeRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	bitfield := Type(1000)
+	require.NoError(RegisterType(bitfield, bitfieldCodec{}))
+	defer unregisterType(bitfield)
+
+	data, err := (&Value{Type: bitfield, Val: []bool{true, false, true}}).Serialize()
+	require.NoError(err)
+	assert.Equal([]byte{1, 0, 1}, data)
+
+	v, err := Deserialize(data, bitfield)
+	require.NoError(err)
+	assert.Equal(bitfield, v.Type)
+	assert.Equal([]bool{true, false, true}, v.Val)
+
+	_, err = Deserialize([]byte{2}, bitfield)
+	assert.EqualError(err, "invalid bit 2")
+
+	_, err = (&Value{Type: bitfield, Val: "foo"}).Serialize()
+	assert.EqualError(err, "expected type []bool, got string")
+
+	assert.NoError((&Value{Type: bitfield, Val: []bool{true}}).Validate())
+	assert.EqualError((&Value{Type: bitfield, Val: "foo"}).Validate(), "expected type []bool, got string")
+}
+
+func TestRegisterTypeCollisions(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.EqualError(RegisterType(Integer, bitfieldCodec{}), "cannot register built-in type *big.Int")
+	assert.Equal(ErrInvalidType, RegisterType(Invalid, bitfieldCodec{}))
+	assert.EqualError(RegisterType(Type(1001), nil), "cannot register nil codec for type 1001")
+
+	assert.NoError(RegisterType(Type(1001), bitfieldCodec{}))
+	defer unregisterType(Type(1001))
+	assert.EqualError(RegisterType(Type(1001), bitfieldCodec{}), "type 1001 is already registered")
+}
+
+func TestSupportedTypesIncludesRegistered(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(RegisterType(Type(1002), bitfieldCodec{}))
+	defer unregisterType(Type(1002))
+
+	types := SupportedTypes()
+	assert.Equal(Type(1002), types[len(types)-1])
+	assert.Contains(types, Integer)
+}
+
+func undeclareType(t Type) {
+	registryLk.Lock()
+	defer registryLk.Unlock()
+	delete(declared, t)
+}
+
+func TestDeclaredTypeWithoutCodec(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	voucher := Type(1004)
+	require.NoError(DeclareType(voucher, "voucher"))
+	defer undeclareType(voucher)
+
+	assert.Equal("voucher", voucher.String())
+	assert.Equal("voucher(foo)", (&Value{Type: voucher, Val: "foo"}).String())
+	typ, err := TypeFromString("voucher")
+	assert.NoError(err)
+	assert.Equal(voucher, typ)
+
+	_, err = (&Value{Type: voucher, Val: "foo"}).Serialize()
+	assert.Equal(ErrNoCodec, errors.Cause(err))
+	assert.EqualError(err, "type voucher: no codec registered")
+
+	_, err = Deserialize([]byte{1}, voucher)
+	assert.Equal(ErrNoCodec, errors.Cause(err))
+
+	// undeclared types are still reported as unrecognized
+	_, err = Deserialize([]byte{1}, Type(1005))
+	assert.EqualError(err, "unrecognized Type: 1005")
+
+	// once a codec is registered the type works
+	require.NoError(RegisterType(voucher, bitfieldCodec{}))
+	defer unregisterType(voucher)
+	_, err = (&Value{Type: voucher, Val: []bool{true}}).Serialize()
+	assert.NoError(err)
+}
+
+func TestDeclareTypeCollisions(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.EqualError(DeclareType(Integer, "foo"), "cannot declare built-in type *big.Int")
+	assert.EqualError(DeclareType(Type(1006), "string"), `type name "string" is already in use`)
+	assert.Equal(ErrInvalidType, DeclareType(Invalid, "foo"))
+
+	assert.NoError(DeclareType(Type(1006), "foo"))
+	defer undeclareType(Type(1006))
+	assert.EqualError(DeclareType(Type(1006), "bar"), "type 1006 is already declared")
+	assert.EqualError(DeclareType(Type(1007), "foo"), `type name "foo" is already in use`)
+}
@@ -0,0 +1,67 @@
+package abi
+
+import (
+	"fmt"
+	"strings"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+	"gx/ipfs/QmZp3eKdYQHHAneECmeK6HhiMwTPufmjC8DuuaGKv3unvx/blake2b-simd"
+)
+
+// SignatureHash computes a 4 byte selector for an ordered list of parameter
+// types. The selector is the prefix of the blake2b-256 hash of the
+// comma-separated type names, so it is stable across reorderings of the Type
+// enum but changes when the parameter order changes.
+//
+// With only 4 bytes collisions between different signatures are possible
+// (they become likely at around 2^16 signatures), so a dispatch table keyed by
+// selector must reject duplicate selectors when it is built.
+func SignatureHash(types []Type) [4]byte {
+	sum := blake2b.Sum256([]byte(strings.Join(DescribeSignature(types), ",")))
+
+	var out [4]byte
+	copy(out[:], sum[:4])
+	return out
+}
+
+// DescribeSignature returns the type names of a parameter list, as returned by
+// Type.String. ParseSignature is the inverse.
+func DescribeSignature(types []Type) []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	return names
+}
+
+// ParseSignature parses a list of type names produced by DescribeSignature.
+func ParseSignature(names []string) ([]Type, error) {
+	types := make([]Type, len(names))
+	for i, name := range names {
+		t, err := TypeFromString(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parameter %d", i)
+		}
+		types[i] = t
+	}
+	return types, nil
+}
+
+// ValidateAgainst checks that vals are a valid call of a method with the given
+// signature: the number of values matches and each value has the declared
+// type. The error names the first mismatch.
+func ValidateAgainst(vals []*Value, signature []Type) error {
+	if len(vals) != len(signature) {
+		return fmt.Errorf("expected %d parameters, but got %d", len(signature), len(vals))
+	}
+
+	for i, v := range vals {
+		if v == nil {
+			return errors.Wrapf(ErrNilValue, "parameter %d", i)
+		}
+		if v.Type != signature[i] {
+			return fmt.Errorf("parameter %d: expected %s, got %s", i, signature[i], v.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureHash(t *testing.T) {
+	assert := assert.New(t)
+
+	a := SignatureHash([]Type{Address, Integer})
+	assert.Equal(a, SignatureHash([]Type{Address, Integer}))
+
+	assert.NotEqual(a, SignatureHash([]Type{Address, Bytes}))
+	assert.NotEqual(a, SignatureHash([]Type{Integer, Address}))
+	assert.NotEqual(a, SignatureHash([]Type{Address}))
+	assert.NotEqual(SignatureHash(nil), SignatureHash([]Type{String}))
+}
+
+func TestSignatureRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	sig := []Type{Address, Integer, Bytes}
+	names := DescribeSignature(sig)
+	assert.Equal([]string{"address.Address", "*big.Int", "[]byte"}, names)
+
+	out, err := ParseSignature(names)
+	assert.NoError(err)
+	assert.Equal(sig, out)
+
+	_, err = ParseSignature([]string{"string", "complex128"})
+	assert.EqualError(err, `parameter 1: unknown type: "complex128"`)
+
+	out, err = ParseSignature(nil)
+	assert.NoError(err)
+	assert.Empty(out)
+}
+
+func TestValidateAgainst(t *testing.T) {
+	assert := assert.New(t)
+
+	signature := []Type{Address, Integer, Bytes}
+	vals := []*Value{
+		NewAddress(address.NewForTestGetter()()),
+		NewInteger(big.NewInt(1)),
+		NewBytes([]byte("beep")),
+	}
+	assert.NoError(ValidateAgainst(vals, signature))
+	assert.NoError(ValidateAgainst(nil, nil))
+
+	err := ValidateAgainst(vals[:2], signature)
+	assert.EqualError(err, "expected 3 parameters, but got 2")
+
+	vals[1] = NewString("1")
+	err = ValidateAgainst(vals, signature)
+	assert.EqualError(err, "parameter 1: expected *big.Int, got string")
+
+	vals[1] = nil
+	err = ValidateAgainst(vals, signature)
+	assert.EqualError(err, "parameter 1: "+ErrNilValue.Error())
+}
@@ -0,0 +1,165 @@
+package abi
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxFrameSize bounds the length read from a frame header, so that a
+// corrupted or malicious header can't make us allocate arbitrary amounts of
+// memory.
+const DefaultMaxFrameSize = 1 << 24
+
+// Encoder writes a stream of length-prefixed abi values to an io.Writer.
+type Encoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewEncoder returns a new Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the serialized value, prefixed with its length as a uvarint.
+func (e *Encoder) Encode(av *Value) error {
+	// leave room for the longest possible length prefix and serialize right
+	// behind it, so that the value is never copied
+	if cap(e.buf) < binary.MaxVarintLen64 {
+		e.buf = make([]byte, binary.MaxVarintLen64, 64)
+	}
+	buf, err := av.SerializeAppend(e.buf[:binary.MaxVarintLen64])
+	if err != nil {
+		return err
+	}
+	e.buf = buf
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(buf)-binary.MaxVarintLen64))
+	start := binary.MaxVarintLen64 - n
+	copy(buf[start:], lenBuf[:n])
+	return writeFull(e.w, buf[start:])
+}
+
+// WriteFrame writes a single value to w, prefixed with its length as a
+// uvarint. The format is the same one an Encoder produces.
+func WriteFrame(w io.Writer, av *Value) error {
+	data, err := av.Serialize()
+	if err != nil {
+		return err
+	}
+
+	return writeFull(w, appendSegment(nil, data))
+}
+
+// ReadFrame reads a single value written by WriteFrame from r. It never reads
+// past the end of the frame. It returns io.EOF if r ends cleanly before the
+// frame, and io.ErrUnexpectedEOF if it ends within it.
+func ReadFrame(r io.Reader, t Type) (*Value, error) {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = &singleByteReader{r: r}
+	}
+
+	data, err := readFrame(br, nil, DefaultMaxFrameSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return Deserialize(data, t)
+}
+
+func writeFull(w io.Writer, data []byte) error {
+	n, err := w.Write(data)
+	if err != nil {
+		return err
+	}
+	if n < len(data) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+// readFrame reads a length prefixed frame into buf, growing it as needed.
+// Frames longer than max are rejected before anything is allocated.
+func readFrame(r byteReader, buf []byte, max uint64) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > max {
+		return nil, fmt.Errorf("frame length %d exceeds limit of %d bytes", n, max)
+	}
+
+	if uint64(cap(buf)) < n {
+		buf = make([]byte, n)
+	}
+	buf = buf[:n]
+
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// singleByteReader implements io.ByteReader on top of a plain io.Reader
+// without buffering, so that nothing past the current frame is consumed.
+type singleByteReader struct {
+	r io.Reader
+	b [1]byte
+}
+
+func (s *singleByteReader) Read(p []byte) (int, error) {
+	return s.r.Read(p)
+}
+
+func (s *singleByteReader) ReadByte() (byte, error) {
+	if _, err := io.ReadFull(s.r, s.b[:]); err != nil {
+		return 0, err
+	}
+	return s.b[0], nil
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// Decoder reads a stream of values written by an Encoder.
+type Decoder struct {
+	// MaxFrameSize is the largest frame, in bytes, Decode accepts. It
+	// defaults to DefaultMaxFrameSize.
+	MaxFrameSize uint64
+
+	r   byteReader
+	buf []byte
+}
+
+// NewDecoder returns a new Decoder reading from r. If r doesn't implement
+// io.ByteReader it is wrapped in a bufio.Reader, and the Decoder may read past
+// the last value it returns.
+func NewDecoder(r io.Reader) *Decoder {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Decoder{MaxFrameSize: DefaultMaxFrameSize, r: br}
+}
+
+// Decode reads the next value from the stream and deserializes it as the given
+// type. It returns io.EOF if the stream ends cleanly before a value.
+func (d *Decoder) Decode(t Type) (*Value, error) {
+	buf, err := readFrame(d.r, d.buf, d.MaxFrameSize)
+	if err != nil {
+		return nil, err
+	}
+	d.buf = buf
+
+	return Deserialize(buf, t)
+}
@@ -0,0 +1,161 @@
+package abi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoderDecoderRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var vals []*Value
+	for i := 0; i < 100; i++ {
+		vals = append(vals,
+			&Value{Type: Integer, Val: big.NewInt(int64(i * 1000))},
+			&Value{Type: String, Val: fmt.Sprintf("value %d", i)},
+			&Value{Type: Bytes, Val: bytes.Repeat([]byte{byte(i)}, i)},
+		)
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	for _, v := range vals {
+		require.NoError(enc.Encode(v))
+	}
+
+	dec := NewDecoder(&buf)
+	for _, v := range vals {
+		out, err := dec.Decode(v.Type)
+		require.NoError(err)
+		assert.True(v.Equals(out), "expected %s, got %s", v, out)
+	}
+
+	_, err := dec.Decode(String)
+	assert.Equal(io.EOF, err)
+}
+
+func TestDecoderTruncatedStream(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(NewEncoder(&buf).Encode(&Value{Type: String, Val: "flugzeug"}))
+
+	truncated := bytes.NewReader(buf.Bytes()[:buf.Len()-1])
+	_, err := NewDecoder(truncated).Decode(String)
+	assert.Equal(io.ErrUnexpectedEOF, err)
+}
+
+func TestDecoderMaxFrameSize(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// a header claiming a 2^60 byte frame followed by nothing
+	header := make([]byte, binary.MaxVarintLen64)
+	header = header[:binary.PutUvarint(header, 1<<60)]
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := NewDecoder(bytes.NewReader(header)).Decode(Bytes)
+	runtime.ReadMemStats(&after)
+	assert.EqualError(err, "frame length 1152921504606846976 exceeds limit of 16777216 bytes")
+	assert.True(after.TotalAlloc-before.TotalAlloc < 1<<20, "allocated %d bytes", after.TotalAlloc-before.TotalAlloc)
+
+	var buf bytes.Buffer
+	require.NoError(NewEncoder(&buf).Encode(&Value{Type: Bytes, Val: make([]byte, 100)}))
+	data := buf.Bytes()
+
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.MaxFrameSize = 10
+	_, err = dec.Decode(Bytes)
+	assert.EqualError(err, "frame length 100 exceeds limit of 10 bytes")
+
+	dec = NewDecoder(bytes.NewReader(data))
+	dec.MaxFrameSize = 100
+	out, err := dec.Decode(Bytes)
+	assert.NoError(err)
+	assert.Len(out.Val, 100)
+}
+
+func TestFramePipe(t *testing.T) {
+	assert := assert.New(t)
+
+	vals := []*Value{
+		{Type: String, Val: "flugzeug"},
+		{Type: Integer, Val: big.NewInt(-1234)},
+		{Type: Bytes, Val: bytes.Repeat([]byte{0xab}, 1000)},
+	}
+
+	r, w := io.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		for _, v := range vals {
+			if err := WriteFrame(w, v); err != nil {
+				errCh <- err
+				return
+			}
+		}
+		errCh <- w.Close()
+	}()
+
+	for _, v := range vals {
+		out, err := ReadFrame(r, v.Type)
+		assert.NoError(err)
+		assert.True(v.Equals(out), "expected %s, got %s", v, out)
+	}
+
+	_, err := ReadFrame(r, String)
+	assert.Equal(io.EOF, err)
+	assert.NoError(<-errCh)
+}
+
+func TestReadFrameFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	// a varint that doesn't terminate within 64 bits
+	_, err := ReadFrame(bytes.NewReader(bytes.Repeat([]byte{0xff}, 11)), String)
+	assert.Error(err)
+
+	_, err = ReadFrame(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x0f}), String)
+	assert.EqualError(err, "frame length 4294967295 exceeds limit of 16777216 bytes")
+
+	_, err = ReadFrame(bytes.NewReader([]byte{0x05, 'a', 'b'}), String)
+	assert.Equal(io.ErrUnexpectedEOF, err)
+}
+
+type shortWriter struct{}
+
+func (shortWriter) Write(p []byte) (int, error) {
+	return len(p) / 2, nil
+}
+
+func TestWriteFrameShortWrite(t *testing.T) {
+	assert.Equal(t, io.ErrShortWrite, WriteFrame(shortWriter{}, &Value{Type: String, Val: "flugzeug"}))
+}
+
+func BenchmarkEncoderEncode(b *testing.B) {
+	vals := []*Value{
+		{Type: Integer, Val: big.NewInt(123456789)},
+		{Type: String, Val: "flugzeug"},
+		{Type: Uint64, Val: uint64(42)},
+	}
+
+	enc := NewEncoder(ioutil.Discard)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, v := range vals {
+			if err := enc.Encode(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package abi
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Character encodings understood by SerializeStringWithEncoding.
+const (
+	StringEncodingUTF8   = "utf-8"
+	StringEncodingLatin1 = "iso-8859-1"
+)
+
+// SerializeStringWithEncoding encodes a string together with the name of the
+// character encoding its bytes are in, so that legacy non UTF-8 strings
+// survive. An empty encoding means UTF-8. The encoding is for off-chain
+// metadata only; actor methods take plain String values.
+func SerializeStringWithEncoding(s string, enc string) ([]byte, error) {
+	if enc == "" {
+		enc = StringEncodingUTF8
+	}
+	if err := checkStringEncoding(s, enc); err != nil {
+		return nil, err
+	}
+
+	return append(appendSegment(nil, []byte(enc)), s...), nil
+}
+
+// DeserializeStringWithEncoding decodes a string encoded by
+// SerializeStringWithEncoding and returns it with the name of its encoding.
+// The bytes are returned as is, they are not converted to UTF-8.
+func DeserializeStringWithEncoding(data []byte) (string, string, error) {
+	enc, rest, err := readSegment(data, 0)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid string encoding tag")
+	}
+
+	s := string(rest)
+	if err := checkStringEncoding(s, string(enc)); err != nil {
+		return "", "", err
+	}
+	return s, string(enc), nil
+}
+
+func checkStringEncoding(s string, enc string) error {
+	switch enc {
+	case StringEncodingUTF8:
+		if !utf8.ValidString(s) {
+			return fmt.Errorf("invalid string encoding: not valid utf-8")
+		}
+		return nil
+	case StringEncodingLatin1:
+		// every byte is a valid latin-1 character
+		return nil
+	default:
+		return fmt.Errorf("unknown string encoding %q", enc)
+	}
+}
@@ -0,0 +1,52 @@
+package abi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringWithEncodingRoundTrip(t *testing.T) {
+	for _, tcase := range []struct {
+		s   string
+		enc string
+		out string
+	}{
+		{"flugzeug ✈", "", StringEncodingUTF8},
+		{"flugzeug ✈", StringEncodingUTF8, StringEncodingUTF8},
+		// "Müller" in latin-1
+		{"M\xfcller", StringEncodingLatin1, StringEncodingLatin1},
+		{"", "", StringEncodingUTF8},
+	} {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		data, err := SerializeStringWithEncoding(tcase.s, tcase.enc)
+		require.NoError(err)
+
+		s, enc, err := DeserializeStringWithEncoding(data)
+		require.NoError(err)
+		assert.Equal(tcase.s, s)
+		assert.Equal(tcase.out, enc)
+	}
+}
+
+func TestStringWithEncodingFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := SerializeStringWithEncoding("M\xfcller", StringEncodingUTF8)
+	assert.EqualError(err, "invalid string encoding: not valid utf-8")
+
+	_, err = SerializeStringWithEncoding("foo", "ebcdic")
+	assert.EqualError(err, `unknown string encoding "ebcdic"`)
+
+	_, _, err = DeserializeStringWithEncoding(append(appendSegment(nil, []byte("ebcdic")), "foo"...))
+	assert.EqualError(err, `unknown string encoding "ebcdic"`)
+
+	_, _, err = DeserializeStringWithEncoding(nil)
+	assert.EqualError(err, "invalid string encoding tag")
+
+	_, _, err = DeserializeStringWithEncoding([]byte{0x10, 'u'})
+	assert.EqualError(err, "invalid string encoding tag")
+}
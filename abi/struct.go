@@ -0,0 +1,99 @@
+package abi
+
+import (
+	"fmt"
+	"sort"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+)
+
+// Field is a named abi value, the building block of a struct.
+type Field struct {
+	Name  string
+	Value *Value
+}
+
+// FieldSchema declares the name and type of a struct field.
+type FieldSchema struct {
+	Name string
+	Type Type
+}
+
+// SerializeStruct encodes a set of named fields. Fields are sorted by name
+// before encoding, so the result doesn't depend on the order they are passed
+// in. Each field is encoded as a length-prefixed name followed by the
+// length-prefixed serialized value.
+func SerializeStruct(fields []Field) ([]byte, error) {
+	sorted := make([]Field, len(fields))
+	copy(sorted, fields)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var out []byte
+	for i, f := range sorted {
+		if i > 0 && sorted[i-1].Name == f.Name {
+			return nil, fmt.Errorf("duplicate field %q", f.Name)
+		}
+
+		data, err := f.Value.Serialize()
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %q", f.Name)
+		}
+
+		out = appendSegment(out, []byte(f.Name))
+		out = appendSegment(out, data)
+	}
+
+	return out, nil
+}
+
+// DeserializeStruct decodes a struct encoded by SerializeStruct according to
+// the given schema. Fields are returned in schema order. It errors if a
+// declared field is missing, the data contains fields not in the schema or
+// the fields aren't sorted by name the way SerializeStruct writes them.
+func DeserializeStruct(data []byte, schema []FieldSchema) ([]Field, error) {
+	segments, err := readSegments(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments)%2 != 0 {
+		return nil, fmt.Errorf("malformed struct: field %q has no value", segments[len(segments)-1])
+	}
+
+	raw := make(map[string][]byte, len(segments)/2)
+	for i := 0; i < len(segments); i += 2 {
+		name := string(segments[i])
+		if i > 0 {
+			switch prev := string(segments[i-2]); {
+			case name == prev:
+				return nil, fmt.Errorf("duplicate field %q", name)
+			case name < prev:
+				return nil, fmt.Errorf("malformed struct: fields out of order")
+			}
+		}
+		raw[name] = segments[i+1]
+	}
+
+	out := make([]Field, 0, len(schema))
+	for _, fs := range schema {
+		fdata, ok := raw[fs.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing field %q", fs.Name)
+		}
+		delete(raw, fs.Name)
+
+		v, err := Deserialize(fdata, fs.Type)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %q", fs.Name)
+		}
+
+		out = append(out, Field{Name: fs.Name, Value: v})
+	}
+
+	for name := range raw {
+		return nil, fmt.Errorf("unexpected field %q", name)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,77 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	addr := address.NewForTestGetter()()
+	fields := []Field{
+		{Name: "to", Value: &Value{Type: Address, Val: addr}},
+		{Name: "amount", Value: &Value{Type: Integer, Val: big.NewInt(500)}},
+	}
+	schema := []FieldSchema{
+		{Name: "to", Type: Address},
+		{Name: "amount", Type: Integer},
+	}
+
+	data, err := SerializeStruct(fields)
+	require.NoError(err)
+
+	out, err := DeserializeStruct(data, schema)
+	require.NoError(err)
+	require.Len(out, 2)
+	assert.Equal("to", out[0].Name)
+	assert.Equal(addr, out[0].Value.Val)
+	assert.Equal("amount", out[1].Name)
+	assert.Equal(big.NewInt(500), out[1].Value.Val)
+
+	// the encoding doesn't depend on the field order
+	reordered, err := SerializeStruct([]Field{fields[1], fields[0]})
+	require.NoError(err)
+	assert.Equal(data, reordered)
+}
+
+func TestStructFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := SerializeStruct([]Field{{Name: "to", Value: &Value{Type: String, Val: "foo"}}})
+	assert.NoError(err)
+
+	_, err = DeserializeStruct(data, []FieldSchema{{Name: "to", Type: String}, {Name: "amount", Type: Integer}})
+	assert.EqualError(err, `missing field "amount"`)
+
+	_, err = DeserializeStruct(data, nil)
+	assert.EqualError(err, `unexpected field "to"`)
+
+	_, err = SerializeStruct([]Field{
+		{Name: "to", Value: &Value{Type: String, Val: "foo"}},
+		{Name: "to", Value: &Value{Type: String, Val: "bar"}},
+	})
+	assert.EqualError(err, `duplicate field "to"`)
+
+	_, err = SerializeStruct([]Field{{Name: "to", Value: &Value{}}})
+	assert.EqualError(err, `field "to": invalid type`)
+
+	to := appendSegment(appendSegment(nil, []byte("to")), []byte("foo"))
+	amount := appendSegment(appendSegment(nil, []byte("amount")), []byte{0x00, 0x01})
+	schema := []FieldSchema{{Name: "to", Type: String}, {Name: "amount", Type: Integer}}
+
+	_, err = DeserializeStruct(append(append([]byte{}, amount...), to...), schema)
+	assert.NoError(err)
+
+	_, err = DeserializeStruct(append(append([]byte{}, to...), amount...), schema)
+	assert.EqualError(err, "malformed struct: fields out of order")
+
+	_, err = DeserializeStruct(append(append([]byte{}, to...), to...), schema)
+	assert.EqualError(err, `duplicate field "to"`)
+}
@@ -0,0 +1,88 @@
+package abi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+)
+
+// SerializeTagged serializes the value prefixed with its type as a single
+// byte, so that it can be decoded without knowing its type up front.
+func SerializeTagged(av *Value) ([]byte, error) {
+	if av == nil {
+		return nil, ErrNilValue
+	}
+	if av.Type > math.MaxUint8 {
+		return nil, fmt.Errorf("type %d does not fit into a tag byte", av.Type)
+	}
+
+	return av.SerializeAppend([]byte{byte(av.Type)})
+}
+
+// DeserializeTagged decodes a value produced by SerializeTagged.
+func DeserializeTagged(data []byte) (*Value, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("missing type tag")
+	}
+
+	t := Type(data[0])
+	if _, ok := typeTable[t]; !ok {
+		_, registered := registeredCodec(t)
+		_, named := declaredName(t)
+		if !registered && !named {
+			return nil, fmt.Errorf("unknown type tag %d", data[0])
+		}
+	}
+
+	// declared types without a codec fail with ErrNoCodec
+	return Deserialize(data[1:], t)
+}
+
+// SerializeTaggedList encodes a list of values of any types. The number of
+// values is stored first, followed by the length-prefixed SerializeTagged
+// encoding of each value. An empty list is encoded as a zero count.
+func SerializeTaggedList(vals []*Value) ([]byte, error) {
+	var buf [binary.MaxVarintLen64]byte
+	out := append([]byte{}, buf[:binary.PutUvarint(buf[:], uint64(len(vals)))]...)
+
+	for i, v := range vals {
+		data, err := SerializeTagged(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list element %d", i)
+		}
+
+		out = appendSegment(out, data)
+	}
+
+	return out, nil
+}
+
+// DeserializeTaggedList decodes a list encoded by SerializeTaggedList. An
+// empty list is returned as an empty, non-nil slice.
+func DeserializeTaggedList(data []byte) ([]*Value, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid list length")
+	}
+
+	segments, err := readSegments(data[n:])
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(segments)) != count {
+		return nil, fmt.Errorf("expected %d list elements, but got %d", count, len(segments))
+	}
+
+	out := make([]*Value, 0, len(segments))
+	for i, seg := range segments {
+		v, err := DeserializeTagged(seg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list element %d", i)
+		}
+		out = append(out, v)
+	}
+
+	return out, nil
+}
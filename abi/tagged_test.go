@@ -0,0 +1,124 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+
+	"github.com/filecoin-project/go-filecoin/address"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaggedRoundTrip(t *testing.T) {
+	vals := canonicalValues(t)
+
+	// every built-in type is covered
+	seen := make(map[Type]bool)
+	for _, v := range vals {
+		seen[v.Type] = true
+	}
+	require.Len(t, seen, len(typeTable))
+
+	for name, v := range vals {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			data, err := SerializeTagged(v)
+			require.NoError(err)
+			assert.Equal(byte(v.Type), data[0])
+
+			out, err := DeserializeTagged(data)
+			require.NoError(err)
+			assert.True(v.Equals(out), "expected %s, got %s", v, out)
+		})
+	}
+}
+
+func TestTaggedFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := DeserializeTagged(nil)
+	assert.EqualError(err, "missing type tag")
+
+	_, err = DeserializeTagged([]byte{0xfe, 0x01})
+	assert.EqualError(err, "unknown type tag 254")
+
+	_, err = DeserializeTagged([]byte{byte(Invalid)})
+	assert.EqualError(err, "unknown type tag 0")
+
+	ticket := Type(200)
+	assert.NoError(DeclareType(ticket, "ticket"))
+	defer undeclareType(ticket)
+	_, err = DeserializeTagged([]byte{byte(ticket), 0x01})
+	assert.Equal(ErrNoCodec, errors.Cause(err))
+
+	_, err = SerializeTagged(&Value{Type: Type(1000), Val: "foo"})
+	assert.EqualError(err, "type 1000 does not fit into a tag byte")
+
+	_, err = SerializeTagged(nil)
+	assert.Equal(ErrNilValue, err)
+}
+
+func TestTaggedListRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	vals := []*Value{
+		NewAddress(address.NewForTestGetter()()),
+		NewInteger(big.NewInt(-42)),
+		NewBytes([]byte("beep")),
+	}
+
+	data, err := SerializeTaggedList(vals)
+	require.NoError(err)
+
+	out, err := DeserializeTaggedList(data)
+	require.NoError(err)
+	require.Len(out, len(vals))
+	for i := range vals {
+		assert.True(vals[i].Equals(out[i]), "expected %s, got %s", vals[i], out[i])
+	}
+}
+
+func TestTaggedListEmpty(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	empty, err := SerializeTaggedList(nil)
+	require.NoError(err)
+	assert.Equal([]byte{0}, empty)
+
+	out, err := DeserializeTaggedList(empty)
+	require.NoError(err)
+	assert.Equal([]*Value{}, out)
+
+	// a single empty element is not an empty list
+	single, err := SerializeTaggedList([]*Value{NewBytes([]byte{})})
+	require.NoError(err)
+	assert.NotEqual(empty, single)
+
+	out, err = DeserializeTaggedList(single)
+	require.NoError(err)
+	require.Len(out, 1)
+	assert.Equal([]byte{}, out[0].Val)
+}
+
+func TestTaggedListFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := DeserializeTaggedList(nil)
+	assert.EqualError(err, "invalid list length")
+
+	_, err = DeserializeTaggedList([]byte{2, 1, byte(Boolean)})
+	assert.EqualError(err, "expected 2 list elements, but got 1")
+
+	_, err = DeserializeTaggedList([]byte{1, 1, 0xee})
+	assert.EqualError(err, "list element 0: unknown type tag 238")
+
+	_, err = SerializeTaggedList([]*Value{NewString("foo"), nil})
+	assert.EqualError(err, "list element 1: "+ErrNilValue.Error())
+}
@@ -0,0 +1,44 @@
+package abi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// IntegerFromVarint reads a protobuf style varint, an unsigned LEB128 number of
+// at most 64 bits, into an Integer value. data must hold exactly one varint.
+func IntegerFromVarint(data []byte) (*Value, error) {
+	n, k := binary.Uvarint(data)
+	switch {
+	case k == 0:
+		return nil, fmt.Errorf("invalid varint: unexpected end of input")
+	case k < 0:
+		return nil, fmt.Errorf("invalid varint: overflows 64 bits")
+	case k != len(data):
+		return nil, fmt.Errorf("invalid varint: %d trailing bytes", len(data)-k)
+	}
+
+	return NewInteger(new(big.Int).SetUint64(n)), nil
+}
+
+// IntegerToVarint encodes an Integer value as a protobuf style varint. It
+// errors if the value is negative or doesn't fit into 64 bits.
+func (av *Value) IntegerToVarint() ([]byte, error) {
+	intgr, err := av.AsInteger()
+	if err != nil {
+		return nil, err
+	}
+	if intgr == nil {
+		return nil, fmt.Errorf("nil %s value", Integer)
+	}
+	if intgr.Sign() < 0 {
+		return nil, fmt.Errorf("cannot encode negative integer %s as a varint", intgr)
+	}
+	if !intgr.IsUint64() {
+		return nil, fmt.Errorf("integer %s does not fit into a varint", intgr)
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	return buf[:binary.PutUvarint(buf, intgr.Uint64())], nil
+}
@@ -0,0 +1,55 @@
+package abi
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarintRoundTrip(t *testing.T) {
+	for _, n := range []uint64{0, 1, 127, 128, 300, math.MaxUint64} {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		data, err := NewInteger(new(big.Int).SetUint64(n)).IntegerToVarint()
+		require.NoError(err)
+
+		v, err := IntegerFromVarint(data)
+		require.NoError(err)
+		assert.Equal(Integer, v.Type)
+		assert.Equal(0, new(big.Int).SetUint64(n).Cmp(v.Val.(*big.Int)), "expected %d, got %s", n, v)
+	}
+
+	// the example from the protobuf encoding docs
+	data, err := NewInteger(big.NewInt(300)).IntegerToVarint()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xac, 0x02}, data)
+}
+
+func TestVarintFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewInteger(big.NewInt(-1)).IntegerToVarint()
+	assert.EqualError(err, "cannot encode negative integer -1 as a varint")
+
+	_, err = NewInteger(new(big.Int).Lsh(big.NewInt(1), 64)).IntegerToVarint()
+	assert.EqualError(err, "integer 18446744073709551616 does not fit into a varint")
+
+	_, err = NewString("1").IntegerToVarint()
+	assert.EqualError(err, "expected value of type *big.Int, got string")
+
+	_, err = IntegerFromVarint(nil)
+	assert.EqualError(err, "invalid varint: unexpected end of input")
+
+	_, err = IntegerFromVarint([]byte{0xac})
+	assert.EqualError(err, "invalid varint: unexpected end of input")
+
+	_, err = IntegerFromVarint([]byte{0xac, 0x02, 0x00})
+	assert.EqualError(err, "invalid varint: 1 trailing bytes")
+
+	_, err = IntegerFromVarint([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02})
+	assert.EqualError(err, "invalid varint: overflows 64 bits")
+}
@@ -0,0 +1,19 @@
+package abi
+
+import (
+	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
+)
+
+// WalkValues applies fn to every value and returns the results in order. It
+// stops at the first error, which is annotated with the index of the value.
+func WalkValues(vals []*Value, fn func(*Value) (*Value, error)) ([]*Value, error) {
+	out := make([]*Value, 0, len(vals))
+	for i, v := range vals {
+		nv, err := fn(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parameter %d", i)
+		}
+		out = append(out, nv)
+	}
+	return out, nil
+}
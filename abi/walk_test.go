@@ -0,0 +1,50 @@
+package abi
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkValues(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	vals := []*Value{NewInteger(big.NewInt(2)), NewString("foo"), NewInteger(big.NewInt(-21))}
+
+	out, err := WalkValues(vals, func(v *Value) (*Value, error) {
+		if v.Type != Integer {
+			return v, nil
+		}
+		return v.Add(v)
+	})
+	require.NoError(err)
+	require.Len(out, 3)
+	assert.Equal(big.NewInt(4), out[0].Val)
+	assert.Equal("foo", out[1].Val)
+	assert.Equal(big.NewInt(-42), out[2].Val)
+
+	// the inputs are untouched
+	assert.Equal(big.NewInt(2), vals[0].Val)
+}
+
+func TestWalkValuesShortCircuits(t *testing.T) {
+	assert := assert.New(t)
+
+	vals := []*Value{NewString("a"), NewString("b"), NewString("c")}
+
+	var seen int
+	out, err := WalkValues(vals, func(v *Value) (*Value, error) {
+		seen++
+		if v.Val == "b" {
+			return nil, fmt.Errorf("boom")
+		}
+		return v, nil
+	})
+	assert.EqualError(err, "parameter 1: boom")
+	assert.Nil(out)
+	assert.Equal(2, seen)
+}
@@ -7,6 +7,7 @@ import (
 
 	peer "gx/ipfs/QmY5Grm8pJdiSSVsYxx4uNRgweY72EmYwuSDbRnbFok3iY/go-libp2p-peer"
 
+	"github.com/filecoin-project/go-filecoin/abi"
 	"github.com/filecoin-project/go-filecoin/actor"
 	"github.com/filecoin-project/go-filecoin/actor/builtin"
 	. "github.com/filecoin-project/go-filecoin/actor/builtin/miner"
@@ -97,7 +98,9 @@ func TestAskFunctions(t *testing.T) {
 	msg = types.NewMessage(address.TestAddress, minerAddr, 3, nil, "addAsk", pdata)
 	result, err = th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(3))
 	assert.NoError(err)
-	assert.Equal(big.NewInt(1), big.NewInt(0).SetBytes(result.Receipt.Return[0]))
+	askID, err := abi.Deserialize(result.Receipt.Return[0], abi.Integer)
+	require.NoError(err)
+	assert.Equal(big.NewInt(1), askID.Val)
 
 	pdata = actor.MustConvertParams(big.NewInt(1))
 	msg = types.NewMessage(address.TestAddress, minerAddr, 4, types.NewZeroAttoFIL(), "getAsk", pdata)
@@ -296,7 +299,9 @@ func TestMinerCommitSector(t *testing.T) {
 	require.NoError(err)
 	require.NoError(res.ExecutionError)
 	// blockheight was 3
-	require.Equal(types.NewBlockHeight(3), types.NewBlockHeightFromBytes(res.Receipt.Return[0]))
+	h, err := abi.Deserialize(res.Receipt.Return[0], abi.BlockHeight)
+	require.NoError(err)
+	require.Equal(types.NewBlockHeight(3), h.Val)
 
 	// fail because commR already exists
 	res, err = th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, 4, "commitSector", uint64(1), commD, commR, commRStar, th.MakeRandomBytes(int(proofs.SealBytesLen)))
@@ -336,7 +341,9 @@ func TestMinerSubmitPoSt(t *testing.T) {
 	res, err = th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, 9, "getProvingPeriodStart")
 	require.NoError(err)
 	require.NoError(res.ExecutionError)
-	require.Equal(types.NewBlockHeightFromBytes(res.Receipt.Return[0]), types.NewBlockHeight(20003))
+	h, err := abi.Deserialize(res.Receipt.Return[0], abi.BlockHeight)
+	require.NoError(err)
+	require.Equal(types.NewBlockHeight(20003), h.Val)
 
 	// fail to submit inside the proving period
 	proof = th.MakeRandomPoSTProofForTest()
@@ -130,7 +130,7 @@ func MarshalValue(val interface{}) ([]byte, error) {
 		if t == nil {
 			return []byte{}, nil
 		}
-		return t.Bytes(), nil
+		return (&abi.Value{Type: abi.Integer, Val: t}).Serialize()
 	case *types.ChannelID:
 		if t == nil {
 			return []byte{}, nil
@@ -140,7 +140,7 @@ func MarshalValue(val interface{}) ([]byte, error) {
 		if t == nil {
 			return []byte{}, nil
 		}
-		return t.Bytes(), nil
+		return (&abi.Value{Type: abi.BlockHeight, Val: t}).Serialize()
 	case []byte:
 		return t, nil
 	case string:
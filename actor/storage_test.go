@@ -52,7 +52,9 @@ func TestMarshalValue(t *testing.T) {
 			Out []byte
 		}{
 			{In: []byte("hello"), Out: []byte("hello")},
-			{In: big.NewInt(100), Out: big.NewInt(100).Bytes()},
+			{In: big.NewInt(100), Out: []byte{0x00, 100}},
+			{In: big.NewInt(-100), Out: []byte{0x01, 100}},
+			{In: types.NewBlockHeight(0x0102), Out: []byte{0, 0, 0, 0, 0, 0, 0x01, 0x02}},
 			{In: "hello", Out: []byte("hello")},
 		}
 
@@ -8,6 +8,7 @@ import (
 	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
 	"gx/ipfs/QmY5Grm8pJdiSSVsYxx4uNRgweY72EmYwuSDbRnbFok3iY/go-libp2p-peer"
 
+	"github.com/filecoin-project/go-filecoin/abi"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/types"
@@ -93,9 +94,12 @@ func (nm *nodeMiner) GetPower(ctx context.Context, minerAddr address.Address) (*
 		return nil, err
 	}
 
-	power := big.NewInt(0).SetBytes(bytes[0])
+	power, err := abi.DeserializeCanonical(bytes[0], abi.Integer)
+	if err != nil {
+		return nil, err
+	}
 
-	return power, nil
+	return power.Val.(*big.Int), nil
 }
 
 func (nm *nodeMiner) GetPledge(ctx context.Context, minerAddr address.Address) (*big.Int, error) {
@@ -109,9 +113,12 @@ func (nm *nodeMiner) GetPledge(ctx context.Context, minerAddr address.Address) (
 		return nil, err
 	}
 
-	power := big.NewInt(0).SetBytes(bytes[0])
+	power, err := abi.DeserializeCanonical(bytes[0], abi.Integer)
+	if err != nil {
+		return nil, err
+	}
 
-	return power, nil
+	return power.Val.(*big.Int), nil
 }
 
 func (nm *nodeMiner) GetTotalPower(ctx context.Context) (*big.Int, error) {
@@ -125,7 +132,10 @@ func (nm *nodeMiner) GetTotalPower(ctx context.Context) (*big.Int, error) {
 		return nil, err
 	}
 
-	power := big.NewInt(0).SetBytes(bytes[0])
+	power, err := abi.DeserializeCanonical(bytes[0], abi.Integer)
+	if err != nil {
+		return nil, err
+	}
 
-	return power, nil
+	return power.Val.(*big.Int), nil
 }
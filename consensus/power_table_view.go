@@ -7,6 +7,7 @@ import (
 	"gx/ipfs/QmS2aqUZLJp8kF1ihE5rvDGE5LvmKDPnx32w9Z1BW9xLV5/go-ipfs-blockstore"
 	"gx/ipfs/QmVmDhyTTUcQXFD1rRQ64fGLMSAoaQvNH3hwuaCFAPq2hy/errors"
 
+	"github.com/filecoin-project/go-filecoin/abi"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/vm"
@@ -49,10 +50,12 @@ func (v *MarketView) Total(ctx context.Context, st state.Tree, bstore blockstore
 	if ec != 0 {
 		return 0, errors.Errorf("non-zero return code from query message: %d", ec)
 	}
-	res := big.NewInt(0)
-	res.SetBytes(rets[0])
+	res, err := abi.DeserializeCanonical(rets[0], abi.Integer)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to deserialize total storage")
+	}
 
-	return res.Uint64(), nil
+	return res.Val.(*big.Int).Uint64(), nil
 }
 
 // Miner returns the storage that this miner has committed as a uint64.
@@ -71,9 +74,12 @@ func (v *MarketView) Miner(ctx context.Context, st state.Tree, bstore blockstore
 	if ec != 0 {
 		return 0, errors.Errorf("non-zero return code from query message: %d", ec)
 	}
-	ret := big.NewInt(0).SetBytes(rets[0])
+	ret, err := abi.DeserializeCanonical(rets[0], abi.Integer)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to deserialize miner power")
+	}
 
-	return ret.Uint64(), nil
+	return ret.Val.(*big.Int).Uint64(), nil
 }
 
 // HasPower returns true if the provided address belongs to a miner with power
@@ -709,7 +709,12 @@ func (sm *Miner) getProvingPeriodStart() (*types.BlockHeight, error) {
 		return nil, err
 	}
 
-	return types.NewBlockHeightFromBytes(res[0]), nil
+	h, err := abi.Deserialize(res[0], abi.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Val.(*types.BlockHeight), nil
 }
 
 // generatePoSt creates the required PoSt, given a list of sector ids and